@@ -0,0 +1,240 @@
+package githubfs
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v74/github"
+)
+
+// repoArchive holds the extracted content of a repository archive (see [WithArchiveBackend]).
+type repoArchive struct {
+	index   treeIndex
+	blobs   map[string][]byte
+	modTime time.Time
+}
+
+// archive returns the extracted archive of a repository at a revision.
+//
+// The archive is downloaded at first access and kept for the lifetime of the filesystem.
+func (f *FS) archive(ctx context.Context, owner string, repo string, revision string) (*repoArchive, error) {
+	key := owner + "/" + repo + "@" + revision
+
+	f.revisions.mu.Lock()
+	a, ok := f.revisions.archives[key]
+	f.revisions.mu.Unlock()
+
+	if ok {
+		return a, nil
+	}
+
+	// Concurrent first reads share a single download.
+	v, err, _ := f.calls.Do("archive "+key, func() (any, error) {
+		a, err := f.downloadArchive(ctx, ref{owner: owner, repo: repo}, revision)
+		if err != nil {
+			return nil, err
+		}
+
+		f.revisions.mu.Lock()
+		f.revisions.archives[key] = a
+		f.revisions.mu.Unlock()
+
+		return a, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return v.(*repoArchive), nil
+}
+
+// downloadArchive downloads the tarball of a repository and extracts it into memory.
+func (f *FS) downloadArchive(ctx context.Context, r ref, revision string) (*repoArchive, error) {
+	u, _, err := f.client.Repositories.GetArchiveLink(f.ctxFn(ctx), r.owner, r.repo, github.Tarball, &github.RepositoryContentGetOptions{Ref: revision}, 3)
+	if err := handleErr(err, "open", r.string()); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(f.ctxFn(ctx), http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := f.client.Client().Do(req)
+	if err := handleErr(err, "open", r.string()); err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &fs.PathError{Op: "open", Path: r.string(), Err: fmt.Errorf("unexpected status: %s", resp.Status)}
+	}
+
+	gz, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: r.string(), Err: err}
+	}
+	defer gz.Close()
+
+	a := &repoArchive{
+		index: treeIndex{".": nil},
+		blobs: make(map[string][]byte),
+	}
+
+	modes := make(map[string]map[string]string)
+	dirModes := make(map[string]*treeModes)
+
+	tr := tar.NewReader(gz)
+
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, &fs.PathError{Op: "open", Path: r.string(), Err: err}
+		}
+
+		// Entries are nested in a single top-level directory named after the repository and the commit.
+		_, p, ok := strings.Cut(header.Name, "/")
+		p = strings.TrimSuffix(p, "/")
+
+		if !ok || p == "" {
+			if header.Typeflag == tar.TypeDir {
+				a.modTime = header.ModTime
+			}
+
+			continue
+		}
+
+		var mode string
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			mode = "040000"
+
+			if _, ok := a.index[p]; !ok {
+				a.index[p] = nil
+			}
+
+		case tar.TypeReg:
+			mode = "100644"
+			if header.Mode&0o111 != 0 {
+				mode = "100755"
+			}
+
+			content, err := io.ReadAll(tr)
+			if err != nil {
+				return nil, &fs.PathError{Op: "open", Path: r.string(), Err: err}
+			}
+
+			a.blobs[p] = content
+
+		case tar.TypeSymlink:
+			// Symlinks are served as files containing the link target (the way they are stored in git).
+			mode = "120000"
+			a.blobs[p] = []byte(header.Linkname)
+
+		default:
+			continue
+		}
+
+		dir, name := path.Dir(p), path.Base(p)
+
+		if modes[dir] == nil {
+			modes[dir] = make(map[string]string)
+			dirModes[dir] = staticModes(modes[dir])
+		}
+
+		modes[dir][name] = mode
+
+		modTime := fixedModTime(header.ModTime)
+		if f.commitModTimes {
+			modTime = f.modTime(f.ctx, r.join(p), revision)
+		}
+
+		a.index[dir] = append(a.index[dir], &dirEntry{
+			name:    name,
+			isDir:   header.Typeflag == tar.TypeDir,
+			size:    int64(len(a.blobs[p])),
+			modes:   dirModes[dir],
+			modTime: modTime,
+		})
+	}
+
+	for _, entries := range a.index {
+		sortEntries(entries)
+	}
+
+	return a, nil
+}
+
+// openArchive serves a file or directory from the archive of a repository.
+func (f *FS) openArchive(ctx context.Context, r ref, revision string) (fs.File, error) {
+	a, err := f.archive(ctx, r.owner, r.repo, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	p := r.path
+	if p == "" {
+		p = "."
+	}
+
+	if entries, ok := a.index[p]; ok {
+		modTime := fixedModTime(a.modTime)
+		if entry, ok := a.index.lookup(p); ok {
+			modTime = entry.modTime
+		}
+
+		return &dir{
+			name:    path.Base(r.string()),
+			entries: slices.Clone(entries),
+			modTime: modTime,
+		}, nil
+	}
+
+	entry, ok := a.index.lookup(p)
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: r.string(), Err: fs.ErrNotExist}
+	}
+
+	content := a.blobs[p]
+
+	file := &file{
+		name:     entry.name,
+		size:     int64(len(content)),
+		modes:    entry.modes,
+		modTime:  entry.modTime,
+		content:  nopSeekCloser{bytes.NewReader(content)},
+		revision: revision,
+	}
+
+	if f.lfs {
+		if pointer, ok := parseLFSPointer(string(content)); ok {
+			file.size = pointer.size
+			file.reopen = func() (io.ReadCloser, error) {
+				return f.openLFSObject(ctx, r, pointer)
+			}
+			file.openRange = func(off int64, n int64) (io.ReadCloser, error) {
+				return f.openLFSRange(ctx, r, pointer, off, n)
+			}
+
+			if err := file.open(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return f.normalizeFile(file)
+}
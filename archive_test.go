@@ -0,0 +1,105 @@
+package githubfs
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io/fs"
+	"net/http"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestWithArchiveBackend(t *testing.T) {
+	mux, opt := setup(t)
+
+	modTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	var buf bytes.Buffer
+
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+
+	for _, header := range []struct {
+		name    string
+		mode    int64
+		content string
+	}{
+		{name: "owner-repo-abc123/", mode: 0o755},
+		{name: "owner-repo-abc123/README.md", mode: 0o644, content: "# repo"},
+		{name: "owner-repo-abc123/scripts/", mode: 0o755},
+		{name: "owner-repo-abc123/scripts/run.sh", mode: 0o755, content: "#!/bin/sh"},
+	} {
+		typeflag := byte(tar.TypeReg)
+		if header.name[len(header.name)-1] == '/' {
+			typeflag = tar.TypeDir
+		}
+
+		err := tw.WriteHeader(&tar.Header{
+			Typeflag: typeflag,
+			Name:     header.name,
+			Mode:     header.mode,
+			Size:     int64(len(header.content)),
+			ModTime:  modTime,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := tw.Write([]byte(header.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tw.Close()
+	gz.Close()
+
+	var downloads int
+
+	mux.HandleFunc("GET /repos/owner/repo/tarball/main", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://"+r.Host+"/archive.tar.gz", http.StatusFound)
+	})
+	mux.HandleFunc("GET /archive.tar.gz", func(w http.ResponseWriter, r *http.Request) {
+		downloads++
+
+		w.Write(buf.Bytes())
+	})
+
+	fsys := New(opt, WithRepository("owner", "repo"), WithRef("main"), WithArchiveBackend())
+
+	sub, err := fs.Sub(fsys, "scripts")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fstest.TestFS(sub, "run.sh"); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := fs.ReadFile(fsys, "README.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(content) != "# repo" {
+		t.Errorf("unexpected content: %q", content)
+	}
+
+	info, err := fs.Stat(fsys, "scripts/run.sh")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Mode() != 0o755 || !info.ModTime().Equal(modTime) {
+		t.Errorf("unexpected file info: %v %v", info.Mode(), info.ModTime())
+	}
+
+	if _, err := fs.ReadFile(fsys, "scripts"); err == nil {
+		t.Error("expected reading a directory to fail")
+	}
+
+	if downloads != 1 {
+		t.Errorf("expected the archive to be downloaded once, got %d downloads", downloads)
+	}
+}
@@ -0,0 +1,86 @@
+package githubfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"time"
+
+	"github.com/google/go-github/v74/github"
+)
+
+// Bisect binary-searches the commits between a good and a bad ref for the first commit that fails test.
+//
+// The test function receives the filesystem pinned to a candidate commit
+// and reports whether that commit is good (true) or bad (false).
+// Bisect returns the SHA of the first bad commit.
+//
+// The filesystem must be configured with an owner and a repository.
+func (f *FS) Bisect(ctx context.Context, good string, bad string, test func(fs.FS) (bool, error)) (string, error) {
+	if f.ref.owner == "" || f.ref.repo == "" {
+		return "", errors.New("bisect: owner and repository are required")
+	}
+
+	commits, err := f.compareCommits(ctx, good, bad)
+	if err != nil {
+		return "", err
+	}
+
+	if len(commits) == 0 {
+		return "", fmt.Errorf("bisect: no commits between %s and %s", good, bad)
+	}
+
+	// Invariant: commits before lo are good, commits from hi are bad.
+	// The last commit (bad) is assumed to fail.
+	lo, hi := 0, len(commits)-1
+
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+
+		pinned := f.clone(f.ref)
+		pinned.revision = commits[mid]
+		pinned.at = time.Time{}
+
+		ok, err := test(pinned)
+		if err != nil {
+			return "", fmt.Errorf("bisect: testing %s: %w", commits[mid], err)
+		}
+
+		if ok {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+
+	return commits[lo], nil
+}
+
+// compareCommits returns the SHAs of commits reachable from head but not from base in chronological order.
+func (f *FS) compareCommits(ctx context.Context, base string, head string) ([]string, error) {
+	opts := &github.ListOptions{PerPage: f.perPage}
+
+	var commits []string
+	for {
+		comparison, resp, err := f.client.Repositories.CompareCommits(f.ctxFn(ctx), f.ref.owner, f.ref.repo, base, head, opts)
+		if err := handleErr(err, "bisect", ref{owner: f.ref.owner, repo: f.ref.repo}.string()); err != nil {
+			return nil, err
+		}
+
+		if comparison.GetStatus() == "behind" {
+			return nil, fmt.Errorf("bisect: %s is behind %s", head, base)
+		}
+
+		for _, commit := range comparison.Commits {
+			commits = append(commits, commit.GetSHA())
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return commits, nil
+}
@@ -0,0 +1,46 @@
+package githubfs
+
+import (
+	"fmt"
+	"io/fs"
+	"net/http"
+	"testing"
+)
+
+func TestBisect(t *testing.T) {
+	mux, opt := setup(t)
+
+	mux.HandleFunc("GET /repos/owner/repo/compare/{basehead}", func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.PathValue("basehead"), "good...bad"; got != want {
+			t.Errorf("unexpected comparison: got %q, want %q", got, want)
+		}
+
+		w.Write([]byte(`{"status":"ahead","commits":[{"sha":"c1"},{"sha":"c2"},{"sha":"c3"},{"sha":"c4"},{"sha":"c5"}]}`))
+	})
+
+	fsys := New(opt, WithRepository("owner", "repo"))
+
+	var tested []string
+
+	sha, err := fsys.Bisect(t.Context(), "good", "bad", func(fsys fs.FS) (bool, error) {
+		pinned, ok := fsys.(*FS)
+		if !ok {
+			return false, fmt.Errorf("unexpected filesystem type %T", fsys)
+		}
+
+		tested = append(tested, pinned.revision)
+
+		return pinned.revision < "c3", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if sha != "c3" {
+		t.Errorf("unexpected first bad commit: got %q, want %q (tested %v)", sha, "c3", tested)
+	}
+
+	if len(tested) > 3 {
+		t.Errorf("expected at most 3 tests, got %v", tested)
+	}
+}
@@ -0,0 +1,33 @@
+package githubfs
+
+import (
+	"fmt"
+	"net/http"
+	"sync/atomic"
+)
+
+// requestBudget counts requests against a limit (see [WithRequestBudget]).
+type requestBudget struct {
+	limit int64
+	used  atomic.Int64
+}
+
+// budgetTransport rejects requests once the budget is exhausted.
+type budgetTransport struct {
+	base   http.RoundTripper
+	budget *requestBudget
+}
+
+func (t *budgetTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if used := t.budget.used.Add(1); used > t.budget.limit {
+		t.budget.used.Add(-1)
+
+		if req.Body != nil {
+			req.Body.Close()
+		}
+
+		return nil, fmt.Errorf("%w: %d requests", ErrBudgetExceeded, t.budget.limit)
+	}
+
+	return t.base.RoundTrip(req)
+}
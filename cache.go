@@ -0,0 +1,202 @@
+package githubfs
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"path"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v74/github"
+)
+
+// responseCache memoizes API responses for a fixed duration (see [WithCache], [WithMetadataCache] and [WithNegativeCache]).
+type responseCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	now     func() time.Time
+	entries map[string]cacheEntry
+
+	// swept is the number of entries left by the last sweep of expired entries.
+	swept int
+}
+
+type cacheEntry struct {
+	value any
+
+	// expires is zero for entries that never expire.
+	expires time.Time
+}
+
+func newResponseCache(ttl time.Duration) *responseCache {
+	return &responseCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]cacheEntry),
+	}
+}
+
+// get returns the value cached under key, unless it expired.
+//
+// A nil cache never holds values.
+func (c *responseCache) get(key string) (any, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	if !entry.expires.IsZero() && !c.now().Before(entry.expires) {
+		delete(c.entries, key)
+
+		return nil, false
+	}
+
+	return entry.value, true
+}
+
+// set caches a value under key for the TTL of the cache.
+//
+// Immutable values (e.g. responses at a commit SHA) never expire.
+func (c *responseCache) set(key string, value any, immutable bool) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expires time.Time
+	if !immutable {
+		expires = c.now().Add(c.ttl)
+	}
+
+	c.entries[key] = cacheEntry{value: value, expires: expires}
+
+	// Expired entries are otherwise only removed when they are read again:
+	// sweep them whenever the cache doubled in size since the last sweep.
+	if len(c.entries) >= 2*max(c.swept, 64) {
+		c.sweep()
+	}
+}
+
+// sweep removes expired entries.
+func (c *responseCache) sweep() {
+	now := c.now()
+
+	for key, entry := range c.entries {
+		if !entry.expires.IsZero() && !now.Before(entry.expires) {
+			delete(c.entries, key)
+		}
+	}
+
+	c.swept = len(c.entries)
+}
+
+// blobKey returns the key file content is cached under by its git blob SHA.
+//
+// Identical files at different paths (or git references) share the same entry.
+// Although content with a given SHA never changes, entries expire like others,
+// so that long-running processes do not keep every version of every file read.
+func blobKey(sha string) string {
+	return "blob " + sha
+}
+
+// newBlobHash returns a hash computing the git blob SHA of size bytes of content.
+func newBlobHash(size int64) hash.Hash {
+	h := sha1.New()
+	fmt.Fprintf(h, "blob %d\x00", size)
+
+	return h
+}
+
+// blobHash returns the git blob SHA of content.
+func blobHash(content []byte) string {
+	h := newBlobHash(int64(len(content)))
+	h.Write(content)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// knownSHA returns the git blob SHA of the file r points to if it is known without making a request
+// (from a prefetched tree, a cached recursive tree or a cached listing of its parent directory).
+func (f *FS) knownSHA(r ref, revision string) string {
+	if f.eager {
+		f.revisions.mu.Lock()
+		idx := f.revisions.indexes[r.owner+"/"+r.repo+"@"+revision]
+		f.revisions.mu.Unlock()
+
+		if entry, ok := idx.lookup(r.path); ok {
+			if sys, ok := entry.sys.(*github.TreeEntry); ok {
+				return sys.GetSHA()
+			}
+		}
+	}
+
+	if tree := f.cachedTree(ref{owner: r.owner, repo: r.repo}, revision); tree != nil {
+		for _, entry := range tree.Entries {
+			if entry.GetPath() == r.path && entry.GetType() == "blob" {
+				return entry.GetSHA()
+			}
+		}
+	}
+
+	parent := r.parent()
+	base := path.Base(r.path)
+
+	for _, cache := range []*responseCache{f.metadata, f.cache} {
+		v, ok := cache.get(contentsKey(parent, revision))
+		if !ok {
+			continue
+		}
+
+		for _, content := range v.(contentsResponse).dir {
+			if content.GetName() == base && content.GetType() == "file" {
+				return content.GetSHA()
+			}
+		}
+	}
+
+	return ""
+}
+
+// getBlob returns the content of a git blob, fetching it with the Git Blobs API if it is not cached yet.
+func (f *FS) getBlob(ctx context.Context, r ref, sha string) ([]byte, error) {
+	key := blobKey(sha)
+
+	v, ok := f.cache.get(key)
+
+	f.recordCacheLookup(ok, f.cache)
+
+	if ok {
+		return slices.Clone(v.([]byte)), nil
+	}
+
+	v, err, _ := f.calls.Do(key, func() (any, error) {
+		content, _, err := f.client.Git.GetBlobRaw(f.ctxFn(ctx), r.owner, r.repo, sha)
+		if err := handleErr(err, "read", r.string()); err != nil {
+			return nil, err
+		}
+
+		if f.memory.allows(len(content)) {
+			f.cache.set(key, content, false)
+		}
+
+		return content, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.Clone(v.([]byte)), nil
+}
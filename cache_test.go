@@ -0,0 +1,274 @@
+package githubfs
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"path"
+	"testing"
+	"time"
+)
+
+func TestWithCache(t *testing.T) {
+	mux, opt := setup(t)
+
+	var requests int
+
+	mux.HandleFunc("GET /repos/owner/repo/contents/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		switch r.PathValue("path") {
+		case "":
+			w.Write([]byte(`[{"type":"file","name":"README.md","path":"README.md","size":7}]`))
+		default:
+			fileHandler("content")(w, r)
+		}
+	})
+
+	fsys := New(opt, WithRepository("owner", "repo"), WithCache(time.Minute))
+
+	now := time.Now()
+	fsys.cache.now = func() time.Time { return now }
+
+	for range 3 {
+		if _, err := fs.ReadDir(fsys, "."); err != nil {
+			t.Fatal(err)
+		}
+
+		content, err := fs.ReadFile(fsys, "README.md")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(content) != "content" {
+			t.Errorf("unexpected content: %q", content)
+		}
+
+		content[0] = 'X'
+	}
+
+	if requests != 2 {
+		t.Errorf("expected repeated reads to be served from the cache, got %d requests", requests)
+	}
+
+	now = now.Add(time.Minute)
+
+	if _, err := fs.ReadDir(fsys, "."); err != nil {
+		t.Fatal(err)
+	}
+
+	if requests != 3 {
+		t.Errorf("expected expired entries to be fetched again, got %d requests", requests)
+	}
+}
+
+func TestWithNegativeCache(t *testing.T) {
+	mux, opt := setup(t)
+
+	var requests int
+
+	mux.HandleFunc("GET /repos/owner/repo/contents/.golangci.yml", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		http.NotFound(w, r)
+	})
+
+	fsys := New(opt, WithRepository("owner", "repo"), WithNegativeCache(time.Minute))
+
+	now := time.Now()
+	fsys.notFound.now = func() time.Time { return now }
+
+	for range 3 {
+		if _, err := fsys.Open(".golangci.yml"); !errors.Is(err, fs.ErrNotExist) {
+			t.Fatalf("expected file to not exist, got %v", err)
+		}
+
+		if _, err := fs.ReadFile(fsys, ".golangci.yml"); !errors.Is(err, fs.ErrNotExist) {
+			t.Fatalf("expected file to not exist, got %v", err)
+		}
+	}
+
+	if requests != 2 {
+		t.Errorf("expected repeated lookups to be served from the cache, got %d requests", requests)
+	}
+
+	now = now.Add(time.Minute)
+
+	if _, err := fsys.Open(".golangci.yml"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected file to not exist, got %v", err)
+	}
+
+	if requests != 3 {
+		t.Errorf("expected expired entries to be fetched again, got %d requests", requests)
+	}
+}
+
+func TestWithMetadataCache(t *testing.T) {
+	mux, opt := setup(t)
+
+	var listings, reads int
+
+	mux.HandleFunc("GET /repos/owner/repo/contents/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.PathValue("path") {
+		case "":
+			listings++
+
+			w.Write([]byte(`[{"type":"file","name":"README.md","path":"README.md","size":7}]`))
+		default:
+			reads++
+
+			fileHandler("content")(w, r)
+		}
+	})
+
+	fsys := New(opt, WithRepository("owner", "repo"), WithMetadataCache(time.Hour))
+
+	for range 3 {
+		if _, err := fs.ReadDir(fsys, "."); err != nil {
+			t.Fatal(err)
+		}
+
+		info, err := fs.Stat(fsys, "README.md")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if info.Size() != 7 {
+			t.Errorf("unexpected size: %d", info.Size())
+		}
+
+		if _, err := fs.ReadFile(fsys, "README.md"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Stat is answered from the listing fetched by ReadDir
+	if listings != 1 {
+		t.Errorf("expected listings to be cached, got %d requests", listings)
+	}
+
+	if reads != 3 {
+		t.Errorf("expected file contents to be fetched every time, got %d requests", reads)
+	}
+}
+
+func TestWithCacheImmutableRef(t *testing.T) {
+	mux, opt := setup(t)
+
+	const sha = "0123456789abcdef0123456789abcdef01234567"
+
+	var requests int
+
+	mux.HandleFunc("GET /repos/owner/repo/contents/README.md", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		if got := r.URL.Query().Get("ref"); got != sha {
+			t.Errorf("unexpected ref: %q", got)
+		}
+
+		fileHandler("content")(w, r)
+	})
+
+	fsys := New(opt, WithRepository("owner", "repo"), WithRef(sha), WithCache(time.Minute))
+
+	now := time.Now()
+	fsys.cache.now = func() time.Time { return now }
+
+	for range 3 {
+		if _, err := fs.ReadFile(fsys, "README.md"); err != nil {
+			t.Fatal(err)
+		}
+
+		now = now.Add(time.Hour)
+	}
+
+	if requests != 1 {
+		t.Errorf("expected entries at a commit SHA to never expire, got %d requests", requests)
+	}
+}
+
+func TestWithCacheBlobDeduplication(t *testing.T) {
+	mux, opt := setup(t)
+
+	var blobs, raw int
+
+	mux.HandleFunc("GET /repos/owner/repo/contents/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.PathValue("path") {
+		case "", "vendor":
+			w.Write([]byte(`[
+				{"type":"file","name":"LICENSE","path":"LICENSE","sha":"abc","size":3},
+				{"type":"dir","name":"vendor","path":"vendor"}
+			]`))
+		default:
+			raw++
+
+			fileHandler("MIT")(w, r)
+		}
+	})
+	mux.HandleFunc("GET /repos/owner/repo/git/blobs/abc", func(w http.ResponseWriter, r *http.Request) {
+		blobs++
+
+		w.Write([]byte("MIT"))
+	})
+
+	fsys := New(opt, WithRepository("owner", "repo"), WithRef("main"), WithCache(time.Minute))
+
+	dev, err := fsys.SubWithOptions(".", WithRef("dev"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, read := range []struct {
+		fsys *FS
+		name string
+	}{
+		{fsys, "LICENSE"},
+		{fsys, "vendor/LICENSE"},
+		{dev, "LICENSE"},
+	} {
+		if _, err := fs.ReadDir(read.fsys, path.Dir(read.name)); err != nil {
+			t.Fatal(err)
+		}
+
+		content, err := fs.ReadFile(read.fsys, read.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(content) != "MIT" {
+			t.Errorf("unexpected content: %q", content)
+		}
+	}
+
+	if blobs != 1 || raw != 0 {
+		t.Errorf("expected identical files to be fetched once: %d blob requests, %d raw requests", blobs, raw)
+	}
+}
+
+func TestResponseCacheSweep(t *testing.T) {
+	c := newResponseCache(time.Minute)
+
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	c.set("immutable", "value", true)
+
+	for i := range 100 {
+		c.set(fmt.Sprint(i), i, false)
+	}
+
+	now = now.Add(time.Hour)
+
+	for i := range 100 {
+		c.set(fmt.Sprint("new", i), i, false)
+	}
+
+	if len(c.entries) > 101 {
+		t.Errorf("expected expired entries to be swept, got %d entries", len(c.entries))
+	}
+
+	if _, ok := c.get("immutable"); !ok {
+		t.Error("expected immutable entries to be kept")
+	}
+}
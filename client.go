@@ -0,0 +1,149 @@
+package githubfs
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/go-github/v74/github"
+)
+
+// wrapClient returns a copy of a client with its HTTP transport wrapped.
+//
+// The original client is left untouched.
+func wrapClient(c *github.Client, wrap func(http.RoundTripper) http.RoundTripper) *github.Client {
+	httpClient := c.Client()
+
+	transport := httpClient.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	httpClient.Transport = wrap(transport)
+
+	wrapped := github.NewClient(httpClient)
+	wrapped.BaseURL = c.BaseURL
+	wrapped.UploadURL = c.UploadURL
+	wrapped.UserAgent = c.UserAgent
+
+	return wrapped
+}
+
+// ownerTransport sends requests using the transport of the client configured for the owner they target
+// (see [WithClientFor]).
+type ownerTransport struct {
+	base    http.RoundTripper
+	owners  map[string]http.RoundTripper
+	baseURL *url.URL
+}
+
+func (t *ownerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if transport, ok := t.owners[strings.ToLower(t.owner(req.URL))]; ok {
+		// Credentials of the default client must not leak to other owners
+		req = req.Clone(req.Context())
+		req.Header.Del("Authorization")
+
+		return transport.RoundTrip(req)
+	}
+
+	return t.base.RoundTrip(req)
+}
+
+// owner returns the owner a request targets (or an empty string if it cannot be told from the URL).
+func (t *ownerTransport) owner(u *url.URL) string {
+	// Raw content and LFS hosts serve paths starting with the owner
+	if t.baseURL == nil || u.Host != t.baseURL.Host {
+		owner, _, _ := strings.Cut(strings.TrimPrefix(u.Path, "/"), "/")
+
+		return owner
+	}
+
+	p, ok := strings.CutPrefix(u.Path, t.baseURL.Path)
+	if !ok {
+		return ""
+	}
+
+	segments := strings.Split(p, "/")
+	if len(segments) < 2 {
+		return ""
+	}
+
+	switch segments[0] {
+	case "repos", "users", "orgs":
+		return segments[1]
+	}
+
+	return ""
+}
+
+// userAgentTransport sets the User-Agent header of requests (see [WithUserAgent]).
+//
+// Unlike [github.Client.UserAgent], it also applies to requests not sent through the API (e.g. raw content and LFS objects).
+type userAgentTransport struct {
+	base      http.RoundTripper
+	userAgent string
+}
+
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", t.userAgent)
+
+	return t.base.RoundTrip(req)
+}
+
+// transportConfig configures the HTTP transport of the base client (see [WithProxy], [WithRootCAs] and [WithClientCertificate]).
+type transportConfig struct {
+	proxy func(*http.Request) (*url.URL, error)
+	tls   *tls.Config
+}
+
+// configureTransport returns a copy of a client with its HTTP transport configured.
+//
+// Only clients using an [http.Transport] (including the default one) can be configured.
+func configureTransport(c *github.Client, config *transportConfig) (*github.Client, error) {
+	transport := c.Client().Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	base, ok := transport.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("cannot configure transport of type %T", transport)
+	}
+
+	return wrapClient(c, func(http.RoundTripper) http.RoundTripper {
+		t := base.Clone()
+
+		if config.proxy != nil {
+			t.Proxy = config.proxy
+		}
+
+		if config.tls != nil {
+			t.TLSClientConfig = config.tls
+		}
+
+		return t
+	}), nil
+}
+
+// transportConfig returns the transport configuration of the base client, creating it if necessary.
+func (f *FS) transportConfig() *transportConfig {
+	if f.transport == nil {
+		f.transport = &transportConfig{}
+	}
+
+	return f.transport
+}
+
+// tlsConfig returns the TLS configuration of the base client, creating it if necessary.
+func (f *FS) tlsConfig() *tls.Config {
+	config := f.transportConfig()
+
+	if config.tls == nil {
+		config.tls = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	return config.tls
+}
@@ -0,0 +1,147 @@
+// Command githubfs-embed generates a Go file embedding a snapshot of a repository subtree as an [fstest.MapFS].
+//
+// It lets projects vendor a subtree of a repository at build time
+// while using githubfs at runtime behind the same [fs.FS] interface:
+//
+//	//go:generate go run github.com/sagikazarmark/go-github-fs/cmd/githubfs-embed -repo owner/repo -ref <commit SHA> -path docs -o docs_gen.go
+//
+// The package name defaults to the package running go:generate.
+// Set GITHUB_TOKEN to authenticate requests.
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/oauth2"
+
+	githubfs "github.com/sagikazarmark/go-github-fs"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "githubfs-embed:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	flags := flag.NewFlagSet("githubfs-embed", flag.ContinueOnError)
+
+	repository := flags.String("repo", "", "repository to embed (owner/repo)")
+	revision := flags.String("ref", "", "git reference to embed (a commit SHA keeps the output reproducible)")
+	root := flags.String("path", ".", "subtree of the repository to embed")
+	pkg := flags.String("pkg", os.Getenv("GOPACKAGE"), "package name of the generated file")
+	name := flags.String("var", "FS", "variable name of the generated filesystem")
+	output := flags.String("o", "", "output file (defaults to standard output)")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	owner, repo, ok := strings.Cut(*repository, "/")
+	if !ok || owner == "" || repo == "" {
+		return errors.New("-repo must be in the form owner/repo")
+	}
+
+	if *revision == "" {
+		return errors.New("-ref is required")
+	}
+
+	if *pkg == "" {
+		return errors.New("-pkg is required outside of go:generate")
+	}
+
+	opts := []githubfs.Option{
+		githubfs.WithRepository(owner, repo),
+		githubfs.WithRef(*revision),
+	}
+
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		opts = append(opts, githubfs.WithTokenSource(oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})))
+	}
+
+	fsys, err := fs.Sub(githubfs.New(opts...), *root)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+
+	source := fmt.Sprintf("%s@%s:%s", *repository, *revision, *root)
+
+	if err := generate(context.Background(), &buf, fsys, source, *pkg, *name); err != nil {
+		return err
+	}
+
+	if *output == "" {
+		_, err := os.Stdout.Write(buf.Bytes())
+
+		return err
+	}
+
+	return os.WriteFile(*output, buf.Bytes(), 0o644)
+}
+
+// generate writes a Go file declaring a variable holding the files of fsys.
+func generate(ctx context.Context, w io.Writer, fsys fs.FS, source string, pkg string, name string) error {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "// Code generated by githubfs-embed from %s. DO NOT EDIT.\n\n", source)
+	fmt.Fprintf(&buf, "package %s\n\n", pkg)
+	fmt.Fprintf(&buf, "import \"testing/fstest\"\n\n")
+	fmt.Fprintf(&buf, "// %s holds the files of %s.\n", name, source)
+	fmt.Fprintf(&buf, "var %s = fstest.MapFS{\n", name)
+
+	err := githubfs.WalkTree(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// Directories are implied by the files they contain (git does not track empty directories)
+		if d.IsDir() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		content, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(&buf, "\t%s: {Data: []byte(%s), Mode: %#o},\n", strconv.Quote(p), strconv.Quote(string(content)), info.Mode().Perm())
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	buf.WriteString("}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(src)
+
+	return err
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"testing/fstest"
+)
+
+func TestGenerate(t *testing.T) {
+	fsys := fstest.MapFS{
+		"README.md":      {Data: []byte("# docs\n"), Mode: 0o644},
+		"scripts/run.sh": {Data: []byte("#!/bin/sh\n"), Mode: 0o755},
+	}
+
+	var buf bytes.Buffer
+
+	if err := generate(context.Background(), &buf, fsys, "owner/repo@main:docs", "docs", "Docs"); err != nil {
+		t.Fatal(err)
+	}
+
+	const want = `// Code generated by githubfs-embed from owner/repo@main:docs. DO NOT EDIT.
+
+package docs
+
+import "testing/fstest"
+
+// Docs holds the files of owner/repo@main:docs.
+var Docs = fstest.MapFS{
+	"README.md":      {Data: []byte("# docs\n"), Mode: 0644},
+	"scripts/run.sh": {Data: []byte("#!/bin/sh\n"), Mode: 0755},
+}
+`
+
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRunValidatesFlags(t *testing.T) {
+	tests := [][]string{
+		{"-ref", "main", "-pkg", "docs"},
+		{"-repo", "owner", "-ref", "main", "-pkg", "docs"},
+		{"-repo", "owner/repo", "-pkg", "docs"},
+	}
+
+	for _, args := range tests {
+		if err := run(args); err == nil {
+			t.Errorf("expected %v to be rejected", args)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package githubfs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"io"
+)
+
+// Codec transforms blobs before they are written to a [BlobStore] (and back after they are read).
+//
+// Codecs are used for compressing blobs or encrypting them at rest (see [NewCodecStore]).
+// Other algorithms (e.g. zstd) can be plugged in by implementing this interface.
+//
+// Codecs only apply to blob stores: snapshots written by [FS.SaveSnapshot] are not encoded
+// (wrap the writer passed to it and the reader passed to [WithSnapshot] to compress or encrypt them).
+type Codec interface {
+	// Encode encodes the content of the blob stored at key.
+	Encode(key string, content []byte) ([]byte, error)
+
+	// Decode decodes the content of the blob stored at key.
+	Decode(key string, content []byte) ([]byte, error)
+}
+
+// NewCodecStore returns a [BlobStore] that encodes blobs using codecs before storing them in store.
+//
+// Codecs are applied in order when writing and in reverse order when reading,
+// so compression should precede encryption.
+// Keys are not encoded: they remain the SHAs of the original content.
+func NewCodecStore(store BlobStore, codecs ...Codec) BlobStore {
+	return &codecStore{store: store, codecs: codecs}
+}
+
+type codecStore struct {
+	store  BlobStore
+	codecs []Codec
+}
+
+func (s *codecStore) Put(ctx context.Context, key string, content []byte) error {
+	for _, codec := range s.codecs {
+		var err error
+
+		content, err = codec.Encode(key, content)
+		if err != nil {
+			return err
+		}
+	}
+
+	return s.store.Put(ctx, key, content)
+}
+
+func (s *codecStore) Get(ctx context.Context, key string) ([]byte, error) {
+	content, err := s.store.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(s.codecs) - 1; i >= 0; i-- {
+		content, err = s.codecs[i].Decode(key, content)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return content, nil
+}
+
+// GzipCodec returns a [Codec] compressing blobs with gzip.
+func GzipCodec() Codec {
+	return gzipCodec{}
+}
+
+type gzipCodec struct{}
+
+func (gzipCodec) Encode(_ string, content []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	w := gzip.NewWriter(&buf)
+
+	if _, err := w.Write(content); err != nil {
+		return nil, err
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (gzipCodec) Decode(_ string, content []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
+
+// errDecrypt is returned when a blob cannot be decrypted
+// (e.g. because it was encrypted with a different key or stored at a different key).
+var errDecrypt = errors.New("cannot decrypt blob")
+
+// AESCodec returns a [Codec] encrypting blobs with AES-GCM using a user supplied key.
+//
+// The key must be 16, 24 or 32 bytes long (selecting AES-128, AES-192 or AES-256).
+// The key of each blob is authenticated along with its content,
+// so a blob copied to another key in the store fails to decrypt.
+func AESCodec(key []byte) (Codec, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return aesCodec{aead: aead}, nil
+}
+
+type aesCodec struct {
+	aead cipher.AEAD
+}
+
+// Encode prepends a random nonce to the sealed content.
+func (c aesCodec) Encode(key string, content []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return c.aead.Seal(nonce, nonce, content, []byte(key)), nil
+}
+
+func (c aesCodec) Decode(key string, content []byte) ([]byte, error) {
+	if len(content) < c.aead.NonceSize() {
+		return nil, errDecrypt
+	}
+
+	nonce, sealed := content[:c.aead.NonceSize()], content[c.aead.NonceSize():]
+
+	plain, err := c.aead.Open(nil, nonce, sealed, []byte(key))
+	if err != nil {
+		return nil, errDecrypt
+	}
+
+	return plain, nil
+}
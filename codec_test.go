@@ -0,0 +1,59 @@
+package githubfs
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestCodecStore(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+
+	encryption, err := AESCodec(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	backend := &memStore{blobs: make(map[string][]byte)}
+	store := NewCodecStore(backend, GzipCodec(), encryption)
+
+	content := bytes.Repeat([]byte("private content\n"), 100)
+
+	if err := store.Put(t.Context(), "a1", content); err != nil {
+		t.Fatal(err)
+	}
+
+	stored := backend.blobs["a1"]
+
+	if bytes.Contains(stored, []byte("private content")) {
+		t.Error("expected stored blob to be encrypted")
+	}
+
+	if len(stored) >= len(content) {
+		t.Errorf("expected stored blob to be compressed: %d bytes, original %d bytes", len(stored), len(content))
+	}
+
+	got, err := store.Get(t.Context(), "a1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(got, content) {
+		t.Error("decoded blob does not match the original content")
+	}
+
+	otherKey, err := AESCodec(bytes.Repeat([]byte{2}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewCodecStore(backend, GzipCodec(), otherKey).Get(t.Context(), "a1"); !errors.Is(err, errDecrypt) {
+		t.Errorf("expected decryption with a different key to fail, got %v", err)
+	}
+
+	backend.blobs["b2"] = stored
+
+	if _, err := store.Get(t.Context(), "b2"); !errors.Is(err, errDecrypt) {
+		t.Errorf("expected a blob copied to another key to fail decryption, got %v", err)
+	}
+}
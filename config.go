@@ -0,0 +1,214 @@
+package githubfs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Schema validates a decoded configuration document.
+//
+// Compiled schemas of JSON Schema libraries usually satisfy this interface
+// (e.g. *jsonschema.Schema of github.com/santhosh-tekuri/jsonschema/v6).
+type Schema interface {
+	Validate(v any) error
+}
+
+// ConfigValidationError is returned when a configuration file does not match its schema.
+type ConfigValidationError struct {
+	Path string
+
+	// Violations are the reasons the document is invalid.
+	Violations []error
+}
+
+func (e *ConfigValidationError) Error() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s: invalid configuration", e.Path)
+
+	for _, violation := range e.Violations {
+		b.WriteString("\n  - ")
+		b.WriteString(violation.Error())
+	}
+
+	return b.String()
+}
+
+// ConfigFS is a filesystem exposing validated configuration files of another filesystem
+// (typically an [FS] rooted at a configuration repository).
+//
+// Files are JSON documents validated against the schema registered for their path when they are opened.
+// Files without a schema are hidden, so consumers never load unvalidated content.
+type ConfigFS struct {
+	fsys     fs.FS
+	schemas  map[string]Schema
+	patterns []string
+}
+
+// NewConfigFS returns a filesystem exposing the files of fsys that match a schema.
+//
+// The keys of schemas are [path.Match] patterns matched against file paths (e.g. "services/*.json").
+// When several patterns match a path, the most specific one wins:
+// the pattern with the fewest wildcards, then the longest one, then the first in lexical order.
+func NewConfigFS(fsys fs.FS, schemas map[string]Schema) *ConfigFS {
+	patterns := make([]string, 0, len(schemas))
+	for pattern := range schemas {
+		patterns = append(patterns, pattern)
+	}
+
+	slices.SortFunc(patterns, func(a, b string) int {
+		if n, m := wildcards(a), wildcards(b); n != m {
+			return n - m
+		}
+
+		if len(a) != len(b) {
+			return len(b) - len(a)
+		}
+
+		return strings.Compare(a, b)
+	})
+
+	return &ConfigFS{
+		fsys:     fsys,
+		schemas:  schemas,
+		patterns: patterns,
+	}
+}
+
+// wildcards returns the number of wildcards in a [path.Match] pattern.
+func wildcards(pattern string) int {
+	return strings.Count(pattern, "*") + strings.Count(pattern, "?") + strings.Count(pattern, "[")
+}
+
+// Open implements the [fs.FS] interface.
+//
+// Opening an invalid configuration file fails with a [*ConfigValidationError].
+func (c *ConfigFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	file, err := c.fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+
+		return nil, err
+	}
+
+	if info.IsDir() {
+		dir, ok := file.(fs.ReadDirFile)
+		if !ok {
+			file.Close()
+
+			return nil, &fs.PathError{Op: "open", Path: name, Err: errNotDir}
+		}
+
+		return &configDir{ReadDirFile: dir, config: c, name: name}, nil
+	}
+
+	defer file.Close()
+
+	schema := c.schema(name)
+	if schema == nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateConfig(name, content, schema); err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+
+	return &configFile{Reader: bytes.NewReader(content), info: info}, nil
+}
+
+// schema returns the schema of a file (or nil if there is none).
+func (c *ConfigFS) schema(name string) Schema {
+	for _, pattern := range c.patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return c.schemas[pattern]
+		}
+	}
+
+	return nil
+}
+
+// validateConfig decodes a JSON document and validates it against a schema.
+func validateConfig(name string, content []byte, schema Schema) error {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		return &ConfigValidationError{Path: name, Violations: []error{err}}
+	}
+
+	if err := schema.Validate(v); err != nil {
+		violations := []error{err}
+
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			violations = joined.Unwrap()
+		}
+
+		return &ConfigValidationError{Path: name, Violations: violations}
+	}
+
+	return nil
+}
+
+// configFile is a validated configuration file.
+type configFile struct {
+	*bytes.Reader
+
+	info fs.FileInfo
+}
+
+func (f *configFile) Stat() (fs.FileInfo, error) {
+	return f.info, nil
+}
+
+func (f *configFile) Close() error {
+	return nil
+}
+
+// configDir hides files without a schema from directory listings.
+type configDir struct {
+	fs.ReadDirFile
+
+	config *ConfigFS
+	name   string
+}
+
+func (d *configDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	for {
+		batch, err := d.ReadDirFile.ReadDir(n)
+
+		entries := make([]fs.DirEntry, 0, len(batch))
+		for _, entry := range batch {
+			if entry.IsDir() || d.config.schema(path.Join(d.name, entry.Name())) != nil {
+				entries = append(entries, entry)
+			}
+		}
+
+		// An empty result must be explained by an error: keep reading if every entry of a batch is hidden
+		if n > 0 && len(entries) == 0 && len(batch) > 0 && err == nil {
+			continue
+		}
+
+		return entries, err
+	}
+}
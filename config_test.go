@@ -0,0 +1,109 @@
+package githubfs
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+// requiredKeys is a schema requiring an object with the given keys.
+type requiredKeys []string
+
+func (s requiredKeys) Validate(v any) error {
+	object, ok := v.(map[string]any)
+	if !ok {
+		return errors.New("expected an object")
+	}
+
+	var errs []error
+
+	for _, key := range s {
+		if _, ok := object[key]; !ok {
+			errs = append(errs, fmt.Errorf("missing property %q", key))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func TestConfigFS(t *testing.T) {
+	fsys := NewConfigFS(fstest.MapFS{
+		"README.md":               {Data: []byte("# config")},
+		"services/api.json":       {Data: []byte(`{"name":"api","port":8080}`)},
+		"services/broken.json":    {Data: []byte(`{"port":8080}`)},
+		"services/notes.txt":      {Data: []byte("not validated")},
+		"services/malformed.json": {Data: []byte(`{`)},
+	}, map[string]Schema{
+		"services/*.json": requiredKeys{"name", "port"},
+	})
+
+	content, err := fs.ReadFile(fsys, "services/api.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(content) != `{"name":"api","port":8080}` {
+		t.Errorf("unexpected content: %q", content)
+	}
+
+	var validationErr *ConfigValidationError
+
+	if _, err := fs.ReadFile(fsys, "services/broken.json"); !errors.As(err, &validationErr) {
+		t.Errorf("expected validation error, got %v", err)
+	} else if len(validationErr.Violations) != 1 || validationErr.Violations[0].Error() != `missing property "name"` {
+		t.Errorf("unexpected violations: %v", validationErr.Violations)
+	}
+
+	if _, err := fs.ReadFile(fsys, "services/malformed.json"); !errors.As(err, &validationErr) {
+		t.Errorf("expected malformed document to fail validation, got %v", err)
+	}
+
+	for _, name := range []string{"README.md", "services/notes.txt"} {
+		if _, err := fs.ReadFile(fsys, name); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("expected file without schema %q to be hidden, got %v", name, err)
+		}
+	}
+
+	entries, err := fs.ReadDir(fsys, "services")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+
+	if fmt.Sprint(names) != "[api.json broken.json malformed.json]" {
+		t.Errorf("unexpected entries: %v", names)
+	}
+}
+
+func TestConfigFSOverlappingSchemas(t *testing.T) {
+	fsys := NewConfigFS(fstest.MapFS{
+		"services/api.json": {Data: []byte(`{"name":"api"}`)},
+		"services/web.json": {Data: []byte(`{"name":"web"}`)},
+	}, map[string]Schema{
+		"services/*.json":   requiredKeys{"name"},
+		"services/a*.json":  requiredKeys{"name", "port"},
+		"services/api.json": requiredKeys{"name", "owner"},
+		"*/*.json":          requiredKeys{"name", "team"},
+	})
+
+	// Repeat to catch map iteration order leaking into the result.
+	for range 20 {
+		var validationErr *ConfigValidationError
+
+		if _, err := fs.ReadFile(fsys, "services/api.json"); !errors.As(err, &validationErr) {
+			t.Fatalf("expected validation error, got %v", err)
+		} else if len(validationErr.Violations) != 1 || validationErr.Violations[0].Error() != `missing property "owner"` {
+			t.Fatalf("expected the exact pattern to win, got %v", validationErr.Violations)
+		}
+
+		if _, err := fs.ReadFile(fsys, "services/web.json"); err != nil {
+			t.Fatalf("expected the most specific wildcard pattern to win, got %v", err)
+		}
+	}
+}
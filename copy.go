@@ -0,0 +1,225 @@
+package githubfs
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/google/go-github/v74/github"
+)
+
+// CopyOption configures [CopyDir].
+type CopyOption interface {
+	apply(o *copyOptions)
+}
+
+type copyOptions struct {
+	workers       int
+	skipUnchanged bool
+}
+
+type copyOptionFunc func(o *copyOptions)
+
+func (fn copyOptionFunc) apply(o *copyOptions) {
+	fn(o)
+}
+
+// WithCopyWorkers configures the number of files [CopyDir] downloads concurrently.
+//
+// Defaults to 8.
+func WithCopyWorkers(n int) CopyOption {
+	return copyOptionFunc(func(o *copyOptions) {
+		o.workers = max(n, 1)
+	})
+}
+
+// WithSkipUnchanged makes [CopyDir] skip files whose local content matches the git blob SHA of the source file.
+//
+// Files without a known SHA (e.g. when fsys is not an [*FS]) are always copied.
+func WithSkipUnchanged() CopyOption {
+	return copyOptionFunc(func(o *copyOptions) {
+		o.skipUnchanged = true
+	})
+}
+
+// CopyDir downloads the subtree at src to the local directory dstDir using a pool of workers.
+//
+// Executable files are created with mode 0755, other files with 0644.
+// Existing files are overwritten. Errors of all files are joined.
+func CopyDir(ctx context.Context, fsys fs.FS, src string, dstDir string, opts ...CopyOption) error {
+	o := copyOptions{workers: 8}
+	for _, opt := range opts {
+		opt.apply(&o)
+	}
+
+	type copyJob struct {
+		name string
+		dst  string
+		info fs.FileInfo
+	}
+
+	var jobs []copyJob
+
+	for entry, err := range walk(ctx, fsys, src) {
+		if err != nil {
+			return err
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// Entry paths are src joined with the path relative to src.
+		rel := "."
+		if src == "." {
+			rel = entry.Path
+		} else if p, ok := strings.CutPrefix(entry.Path, src+"/"); ok {
+			rel = p
+		}
+		dst := filepath.Join(dstDir, filepath.FromSlash(rel))
+
+		if entry.IsDir() {
+			if err := os.MkdirAll(dst, 0o755); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		jobs = append(jobs, copyJob{name: entry.Path, dst: dst, info: info})
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+
+	queue := make(chan copyJob)
+
+	for range min(o.workers, max(len(jobs), 1)) {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for job := range queue {
+				if err := copyFile(ctx, fsys, job.name, job.dst, job.info, o.skipUnchanged); err != nil {
+					mu.Lock()
+					errs = append(errs, err)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	for _, job := range jobs {
+		select {
+		case queue <- job:
+		case <-ctx.Done():
+			close(queue)
+			wg.Wait()
+
+			return ctx.Err()
+		}
+	}
+
+	close(queue)
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// copyFile copies a single file to dst.
+func copyFile(ctx context.Context, fsys fs.FS, name string, dst string, info fs.FileInfo, skipUnchanged bool) error {
+	if skipUnchanged {
+		if sha := blobSHA(info); sha != "" {
+			if local, err := localBlobSHA(dst); err == nil && local == sha {
+				return nil
+			}
+		}
+	}
+
+	mode := fs.FileMode(0o644)
+	if info.Mode().IsRegular() && info.Mode()&0o111 != 0 {
+		mode = 0o755
+	}
+
+	src, err := openContext(ctx, fsys, name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, src); err != nil {
+		out.Close()
+
+		return &fs.PathError{Op: "copy", Path: name, Err: err}
+	}
+
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	// The mode of existing files is not changed by OpenFile.
+	return os.Chmod(dst, mode)
+}
+
+// openContext opens a file using ctx for requests (including reading the file) if fsys is an [*FS].
+func openContext(ctx context.Context, fsys fs.FS, name string) (fs.File, error) {
+	if f, ok := fsys.(*FS); ok {
+		return f.OpenContext(ctx, name)
+	}
+
+	return fsys.Open(name)
+}
+
+// blobSHA returns the git blob SHA of a file, if known.
+func blobSHA(info fs.FileInfo) string {
+	switch sys := info.Sys().(type) {
+	case *github.TreeEntry:
+		return sys.GetSHA()
+	case *github.RepositoryContent:
+		return sys.GetSHA()
+	}
+
+	return ""
+}
+
+// localBlobSHA computes the git blob SHA of a local file.
+func localBlobSHA(name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	h := newBlobHash(info.Size())
+
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
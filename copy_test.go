@@ -0,0 +1,115 @@
+package githubfs
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCopyDir(t *testing.T) {
+	mux, opt := setup(t)
+
+	blobSHA := func(content string) string {
+		sum := sha1.Sum(fmt.Appendf(nil, "blob %d\x00%s", len(content), content))
+
+		return hex.EncodeToString(sum[:])
+	}
+
+	mux.HandleFunc("GET /repos/owner/repo/git/trees/{sha...}", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"tree":[
+			{"path":"README.md","type":"blob","mode":"100644","size":6,"sha":%q},
+			{"path":"scripts","type":"tree","mode":"040000"},
+			{"path":"scripts/run.sh","type":"blob","mode":"100755","size":9,"sha":%q}
+		]}`, blobSHA("# docs"), blobSHA("#!/bin/sh"))
+	})
+
+	var downloads []string
+
+	mux.HandleFunc("GET /repos/owner/repo/contents/docs/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		downloads = append(downloads, r.PathValue("path"))
+
+		switch r.PathValue("path") {
+		case "README.md":
+			fileHandler("# docs")(w, r)
+		case "scripts/run.sh":
+			fileHandler("#!/bin/sh")(w, r)
+		}
+	})
+
+	dst := t.TempDir()
+
+	// Unchanged files are skipped
+	if err := os.WriteFile(filepath.Join(dst, "README.md"), []byte("# docs"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	fsys := New(opt, WithRepository("owner", "repo"))
+
+	if err := CopyDir(t.Context(), fsys, "docs", dst, WithCopyWorkers(2), WithSkipUnchanged()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(downloads) != 1 || downloads[0] != "scripts/run.sh" {
+		t.Errorf("expected only changed files to be downloaded, got %v", downloads)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dst, "scripts", "run.sh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(content) != "#!/bin/sh" {
+		t.Errorf("unexpected content: %q", content)
+	}
+
+	info, err := os.Stat(filepath.Join(dst, "scripts", "run.sh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Mode().Perm()&0o111 == 0 {
+		t.Errorf("expected executable bit to be preserved, got %v", info.Mode())
+	}
+}
+
+func TestCopyDirRoot(t *testing.T) {
+	mux, opt := setup(t)
+
+	mux.HandleFunc("GET /repos/owner/repo/git/trees/{sha...}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"tree":[
+			{"path":".github","type":"tree","mode":"040000"},
+			{"path":".github/workflows","type":"tree","mode":"040000"},
+			{"path":".github/workflows/ci.yml","type":"blob","mode":"100644","size":3}
+		]}`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/contents/.github/workflows/ci.yml", fileHandler("on:"))
+
+	fsys := New(opt, WithRepository("owner", "repo"))
+	dst := t.TempDir()
+
+	if err := CopyDir(t.Context(), fsys, ".", dst); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dst, ".github", "workflows", "ci.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(content) != "on:" {
+		t.Errorf("unexpected content: %q", content)
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	if err := CopyDir(ctx, fsys, ".", t.TempDir()); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,188 @@
+package githubfs
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"golang.org/x/oauth2"
+)
+
+// credentialTransport detects credentials expiring during a session (a 401 after requests succeeded before)
+// and retries the request once after reauthenticating.
+//
+// Credentials are told apart by the owner a request targets (see [WithClientFor])
+// and the token resolved for it (see [WithTokenFunc]).
+type credentialTransport struct {
+	base   http.RoundTripper
+	reauth func(ctx context.Context) error
+	owner  func(u *url.URL) string
+
+	// authenticated holds the keys of credentials a request succeeded with.
+	authenticated sync.Map
+}
+
+// credentialUse records the token a request was authenticated with by an inner transport (see [tokenFuncTransport]).
+type credentialUse struct {
+	token string
+}
+
+type credentialUseKey struct{}
+
+func (t *credentialTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	use := new(credentialUse)
+	req = req.WithContext(context.WithValue(req.Context(), credentialUseKey{}, use))
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	key := t.key(req, use)
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		if resp.StatusCode < http.StatusBadRequest {
+			t.authenticated.Store(key, true)
+		}
+
+		return resp, nil
+	}
+
+	// Credentials that never worked are invalid, not expired.
+	if _, ok := t.authenticated.Load(key); !ok {
+		return resp, nil
+	}
+
+	resp.Body.Close()
+
+	if t.reauth != nil && (req.Body == nil || req.GetBody != nil) {
+		if err := t.reauth(req.Context()); err != nil {
+			return nil, fmt.Errorf("%w: %w: reauthenticating: %w", ErrCredentialExpired, fs.ErrPermission, err)
+		}
+
+		retry := req.Clone(req.Context())
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+
+			retry.Body = body
+		}
+
+		resp, err := t.base.RoundTrip(retry)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode != http.StatusUnauthorized {
+			return resp, nil
+		}
+
+		resp.Body.Close()
+	}
+
+	return nil, fmt.Errorf("%w: %w", ErrCredentialExpired, fs.ErrPermission)
+}
+
+// key identifies the credentials a request was sent with.
+//
+// Tokens are hashed, so that they are not kept in memory longer than needed.
+func (t *credentialTransport) key(req *http.Request, use *credentialUse) string {
+	var owner string
+	if t.owner != nil {
+		owner = strings.ToLower(t.owner(req.URL))
+	}
+
+	if use.token == "" {
+		return owner
+	}
+
+	sum := sha256.Sum256([]byte(use.token))
+
+	return owner + " " + hex.EncodeToString(sum[:])
+}
+
+// hostTransport sends requests to the API host through auth and other requests (e.g. to the raw content host)
+// directly, so that credentials do not leak to other hosts.
+type hostTransport struct {
+	base http.RoundTripper
+	auth http.RoundTripper
+	host string
+}
+
+func (t *hostTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host != t.host {
+		return t.base.RoundTrip(req)
+	}
+
+	return t.auth.RoundTrip(req)
+}
+
+// tokenFuncTransport authenticates requests with a token resolved for each request (see [WithTokenFunc]).
+type tokenFuncTransport struct {
+	base http.RoundTripper
+	fn   func(ctx context.Context) (string, error)
+}
+
+func (t *tokenFuncTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.fn(req.Context())
+	if err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+
+		return nil, fmt.Errorf("resolving token: %w", err)
+	}
+
+	if token == "" {
+		return t.base.RoundTrip(req)
+	}
+
+	if use, ok := req.Context().Value(credentialUseKey{}).(*credentialUse); ok {
+		use.token = token
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	return t.base.RoundTrip(req)
+}
+
+// resettableTokenSource caches tokens like [oauth2.ReuseTokenSource],
+// but the cache can be dropped when the server rejects a token before it expires.
+type resettableTokenSource struct {
+	src oauth2.TokenSource
+
+	mu    sync.Mutex
+	reuse oauth2.TokenSource
+}
+
+func newResettableTokenSource(src oauth2.TokenSource) *resettableTokenSource {
+	return &resettableTokenSource{
+		src:   src,
+		reuse: oauth2.ReuseTokenSource(nil, src),
+	}
+}
+
+func (s *resettableTokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	reuse := s.reuse
+	s.mu.Unlock()
+
+	return reuse.Token()
+}
+
+func (s *resettableTokenSource) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reuse = oauth2.ReuseTokenSource(nil, s.src)
+}
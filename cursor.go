@@ -0,0 +1,113 @@
+package githubfs
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+// CursorDir is implemented by directory handles of paginated listings (the repositories of an owner).
+//
+// Listings are requested in a deterministic order, so a cursor can be used to resume reading a listing
+// in another request or process (see [FS.OpenAtCursor]) without listing it from the beginning.
+type CursorDir interface {
+	fs.ReadDirFile
+
+	// Cursor returns an opaque cursor pointing at the next entry to be read by ReadDir.
+	//
+	// Returns an empty string at the end of the listing and for directories that are not paginated.
+	Cursor() string
+}
+
+// OpenAtCursor opens a paginated directory (the repositories of an owner) at a cursor returned by [CursorDir.Cursor].
+//
+// An empty cursor opens the directory at the beginning (like [FS.Open]).
+func (f *FS) OpenAtCursor(name string, cursor string) (fs.File, error) {
+	if cursor == "" {
+		return f.open(f.ctx, name)
+	}
+
+	r, err := f.resolve("open", name)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.repo != "" {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fmt.Errorf("directory is not paginated: %w", fs.ErrInvalid)}
+	}
+
+	start, err := decodeListCursor(cursor)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+
+	return f.listRepositories(f.ctx, r.owner, start)
+}
+
+// listCursor points at an entry of a paginated listing.
+type listCursor struct {
+	page  int
+	index int
+
+	// after is the GraphQL cursor of the page (see [WithGraphQLBackend]).
+	after string
+}
+
+func (c listCursor) encode() string {
+	s := fmt.Sprintf("%d:%d", c.page, c.index)
+	if c.after != "" {
+		s += ":" + c.after
+	}
+
+	return base64.RawURLEncoding.EncodeToString([]byte(s))
+}
+
+func decodeListCursor(cursor string) (listCursor, error) {
+	invalid := fmt.Errorf("invalid cursor: %w", fs.ErrInvalid)
+
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return listCursor{}, invalid
+	}
+
+	page, index, ok := strings.Cut(string(b), ":")
+	if !ok {
+		return listCursor{}, invalid
+	}
+
+	var c listCursor
+
+	index, c.after, _ = strings.Cut(index, ":")
+
+	c.page, err = strconv.Atoi(page)
+	if err != nil || c.page < 1 {
+		return listCursor{}, invalid
+	}
+
+	c.index, err = strconv.Atoi(index)
+	if err != nil || c.index < 0 {
+		return listCursor{}, invalid
+	}
+
+	return c, nil
+}
+
+// listPages records where pages start in a listing (page number and index of its first entry).
+type listPages []listCursor
+
+// cursor returns the cursor of the entry at index i.
+func (p listPages) cursor(i int) listCursor {
+	var c listCursor
+
+	for _, start := range p {
+		if start.index > i {
+			break
+		}
+
+		c = listCursor{page: start.page, index: i - start.index, after: start.after}
+	}
+
+	return c
+}
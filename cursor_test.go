@@ -0,0 +1,81 @@
+package githubfs
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestOpenAtCursor(t *testing.T) {
+	mux, opt := setup(t)
+
+	var pages []string
+
+	mux.HandleFunc("GET /users/owner/repos", func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		pages = append(pages, page)
+
+		switch page {
+		case "", "1":
+			w.Header().Set("Link", `<https://api.github.com/users/owner/repos?page=2>; rel="next"`)
+			w.Write([]byte(`[{"name":"a"},{"name":"b"}]`))
+		case "2":
+			w.Header().Set("Link", `<https://api.github.com/users/owner/repos?page=3>; rel="next"`)
+			w.Write([]byte(`[{"name":"c"},{"name":"d"}]`))
+		case "3":
+			w.Write([]byte(`[{"name":"e"}]`))
+		}
+	})
+
+	fsys := New(opt, WithOwner("owner"))
+
+	file, err := fsys.Open(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := file.(CursorDir)
+
+	if _, err := dir.ReadDir(3); err != nil {
+		t.Fatal(err)
+	}
+
+	cursor := dir.Cursor()
+	file.Close()
+
+	pages = nil
+
+	file, err = fsys.OpenAtCursor(".", cursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	entries, err := file.(CursorDir).ReadDir(-1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+
+	if want := []string{"d", "e"}; !slices.Equal(names, want) {
+		t.Errorf("unexpected entries after resuming: got %v, want %v", names, want)
+	}
+
+	if want := []string{"2", "3"}; !slices.Equal(pages, want) {
+		t.Errorf("expected listing to resume at the page of the cursor, got pages %v", pages)
+	}
+
+	if cursor := file.(CursorDir).Cursor(); cursor != "" {
+		t.Errorf("expected empty cursor at the end of the listing, got %q", cursor)
+	}
+
+	if _, err := fsys.OpenAtCursor(".", "invalid"); !errors.Is(err, fs.ErrInvalid) {
+		t.Errorf("expected invalid cursor to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+package githubfs
+
+import (
+	"context"
+	"path"
+
+	"github.com/google/go-github/v74/github"
+)
+
+// treeIndex holds the entries of a prefetched tree by directory (relative to the root of the tree).
+//
+// Submodules are omitted.
+type treeIndex map[string][]*dirEntry
+
+// newTreeIndex indexes a recursive tree of the directory r points to.
+func (f *FS) newTreeIndex(r ref, revision string, tree *github.Tree) treeIndex {
+	dirs := treeIndex{".": nil}
+	modes := make(map[string]map[string]string)
+	dirModes := make(map[string]*treeModes)
+
+	for _, entry := range tree.Entries {
+		if entry.GetType() == "commit" {
+			continue
+		}
+
+		dir, name := path.Dir(entry.GetPath()), path.Base(entry.GetPath())
+
+		if modes[dir] == nil {
+			modes[dir] = make(map[string]string)
+			dirModes[dir] = staticModes(modes[dir])
+		}
+
+		modes[dir][name] = entry.GetMode()
+
+		dirs[dir] = append(dirs[dir], &dirEntry{
+			name:    name,
+			isDir:   entry.GetType() == "tree",
+			size:    int64(entry.GetSize()),
+			modes:   dirModes[dir],
+			modTime: f.modTime(f.ctx, r.join(entry.GetPath()), revision),
+			sys:     entry,
+		})
+
+		if entry.GetType() == "tree" {
+			if _, ok := dirs[entry.GetPath()]; !ok {
+				dirs[entry.GetPath()] = nil
+			}
+		}
+	}
+
+	for _, entries := range dirs {
+		sortEntries(entries)
+	}
+
+	return dirs
+}
+
+// lookup returns the entry at a path (relative to the root of the tree).
+func (idx treeIndex) lookup(p string) (*dirEntry, bool) {
+	for _, entry := range idx[path.Dir(p)] {
+		if entry.name == path.Base(p) {
+			return entry, true
+		}
+	}
+
+	return nil, false
+}
+
+// eagerTree returns the index of the entire tree of a repository (see [WithEagerTree]).
+//
+// The tree is fetched at first access and kept for the lifetime of the filesystem.
+func (f *FS) eagerTree(ctx context.Context, owner string, repo string, revision string) (treeIndex, error) {
+	key := owner + "/" + repo + "@" + revision
+
+	f.revisions.mu.Lock()
+	idx, ok := f.revisions.indexes[key]
+	f.revisions.mu.Unlock()
+
+	if ok {
+		return idx, nil
+	}
+
+	// Concurrent first reads share a single tree request.
+	v, err, _ := f.calls.Do("tree "+key, func() (any, error) {
+		r := ref{owner: owner, repo: repo}
+
+		tree, err := f.getCompleteTree(ctx, "open", r, revision)
+		if err != nil {
+			return nil, err
+		}
+
+		idx := f.newTreeIndex(r, revision, tree)
+
+		f.revisions.mu.Lock()
+		f.revisions.indexes[key] = idx
+		f.revisions.mu.Unlock()
+
+		return idx, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return v.(treeIndex), nil
+}
+
+// staticModes returns a mode resolver for modes known upfront (e.g. from a prefetched tree).
+func staticModes(modes map[string]string) *treeModes {
+	return &treeModes{
+		load: func() (map[string]string, error) {
+			return modes, nil
+		},
+	}
+}
@@ -0,0 +1,121 @@
+package githubfs
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithEagerTree(t *testing.T) {
+	mux, opt := setup(t)
+
+	var requests []string
+
+	mux.HandleFunc("GET /repos/owner/repo", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"name":"repo"}`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/git/trees/{sha...}", func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, "tree "+r.PathValue("sha"))
+
+		w.Write([]byte(`{"tree":[
+			{"path":"README.md","type":"blob","mode":"100644","size":5},
+			{"path":"docs","type":"tree","mode":"040000"},
+			{"path":"docs/guides","type":"tree","mode":"040000"},
+			{"path":"docs/guides/intro.md","type":"blob","mode":"100644","size":3},
+			{"path":"docs/run.sh","type":"blob","mode":"100755","size":9}
+		]}`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/contents/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, "contents "+r.PathValue("path"))
+
+		fileHandler("hello")(w, r)
+	})
+
+	fsys := New(opt, WithRepository("owner", "repo"), WithEagerTree())
+
+	var paths []string
+
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		paths = append(paths, path)
+
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{".", "README.md", "docs", "docs/guides", "docs/guides/intro.md", "docs/run.sh"}; !slices.Equal(paths, want) {
+		t.Errorf("unexpected walk: got %v, want %v", paths, want)
+	}
+
+	info, err := fs.Stat(fsys, "docs/run.sh")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Mode() != 0o755 || info.Size() != 9 {
+		t.Errorf("unexpected file info: %v %d", info.Mode(), info.Size())
+	}
+
+	if _, err := fsys.Open("missing.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected missing file to not exist, got %v", err)
+	}
+
+	if want := []string{"tree HEAD"}; !slices.Equal(requests, want) {
+		t.Errorf("expected directories to be served from a single tree request, got %v", requests)
+	}
+
+	content, err := fs.ReadFile(fsys, "README.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(content) != "hello" {
+		t.Errorf("unexpected content: %q", content)
+	}
+}
+
+func TestEagerTreeConcurrent(t *testing.T) {
+	mux, opt := setup(t)
+
+	var requests atomic.Int32
+
+	release := make(chan struct{})
+
+	mux.HandleFunc("GET /repos/owner/repo/git/trees/{sha...}", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		<-release
+
+		w.Write([]byte(`{"tree":[{"path":"README.md","type":"blob","mode":"100644","size":5}]}`))
+	})
+
+	fsys := New(opt, WithRepository("owner", "repo"), WithEagerTree())
+
+	var wg sync.WaitGroup
+
+	for range 8 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if _, err := fs.Stat(fsys, "README.md"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+
+	// Let all readers reach the tree request before answering it.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := requests.Load(); n != 1 {
+		t.Errorf("expected concurrent reads to share a single tree request, got %d", n)
+	}
+}
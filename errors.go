@@ -0,0 +1,226 @@
+package githubfs
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/google/go-github/v74/github"
+)
+
+// Errors returned by the filesystem.
+//
+// Errors are usually wrapped in an [fs.PathError] (along with the original GitHub error, if any),
+// so use [errors.Is] to check for them.
+var (
+	// ErrRateLimited is returned when GitHub rejects a request due to primary or secondary rate limits.
+	ErrRateLimited = errors.New("rate limited")
+
+	// ErrTooLarge is returned when a file is too large to be served by the GitHub API.
+	ErrTooLarge = errors.New("file too large")
+
+	// ErrBlocked is returned when access to a resource is blocked by GitHub (e.g. due to a DMCA takedown).
+	ErrBlocked = errors.New("access blocked")
+
+	// ErrOffline is returned when GitHub cannot be reached.
+	ErrOffline = errors.New("github unreachable")
+
+	// ErrNotModified is returned when a conditional request reports that a resource has not changed.
+	ErrNotModified = errors.New("not modified")
+
+	// ErrProtectedBranch is returned when GitHub rejects an operation due to branch protection or repository rules.
+	ErrProtectedBranch = errors.New("protected branch")
+
+	// ErrModified is returned when a resource changed since it was read
+	// (e.g. an update based on an outdated SHA or a failed precondition).
+	ErrModified = errors.New("modified concurrently")
+
+	// ErrBudgetExceeded is returned when the configured API request budget is exhausted.
+	ErrBudgetExceeded = errors.New("request budget exceeded")
+
+	// ErrCredentialExpired is returned when GitHub starts rejecting credentials that worked earlier in the session.
+	//
+	// It is also reported as [fs.ErrPermission].
+	ErrCredentialExpired = errors.New("credentials expired")
+)
+
+// PermissionError is returned when GitHub denies access to a resource,
+// with details to diagnose missing token scopes and SAML single sign-on authorizations.
+//
+// It is reported as [fs.ErrPermission].
+type PermissionError struct {
+	// AcceptedScopes are the OAuth scopes the endpoint accepts (from the X-Accepted-OAuth-Scopes header).
+	AcceptedScopes []string
+
+	// TokenScopes are the OAuth scopes of the token (from the X-OAuth-Scopes header).
+	TokenScopes []string
+
+	// SSOOrganization is the organization requiring the token to be authorized for SAML single sign-on.
+	SSOOrganization string
+
+	// SSOURL is the URL to authorize the token for SAML single sign-on at.
+	SSOURL string
+}
+
+func newPermissionError(resp *http.Response) *PermissionError {
+	e := &PermissionError{}
+
+	if resp == nil {
+		return e
+	}
+
+	e.AcceptedScopes = parseScopes(resp.Header.Get("X-Accepted-OAuth-Scopes"))
+	e.TokenScopes = parseScopes(resp.Header.Get("X-OAuth-Scopes"))
+
+	// Format: "required; url=https://github.com/orgs/<org>/sso?authorization_request=..."
+	if sso := resp.Header.Get("X-GitHub-SSO"); strings.HasPrefix(sso, "required") {
+		for _, param := range strings.Split(sso, ";") {
+			if u, ok := strings.CutPrefix(strings.TrimSpace(param), "url="); ok {
+				e.SSOURL = u
+			}
+		}
+
+		if u, err := url.Parse(e.SSOURL); err == nil {
+			if org, ok := strings.CutPrefix(u.Path, "/orgs/"); ok {
+				e.SSOOrganization, _, _ = strings.Cut(org, "/")
+			}
+		}
+	}
+
+	return e
+}
+
+func parseScopes(header string) []string {
+	var scopes []string
+
+	for _, scope := range strings.Split(header, ",") {
+		if scope := strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	return scopes
+}
+
+func (e *PermissionError) Error() string {
+	msg := fs.ErrPermission.Error()
+
+	if e.SSOURL != "" {
+		msg += ": token must be authorized for SAML single sign-on"
+
+		if e.SSOOrganization != "" {
+			msg += " by " + e.SSOOrganization
+		}
+
+		msg += " at " + e.SSOURL
+	}
+
+	if len(e.AcceptedScopes) > 0 {
+		msg += fmt.Sprintf(": accepted scopes: %s (token scopes: %s)", strings.Join(e.AcceptedScopes, ", "), strings.Join(e.TokenScopes, ", "))
+	}
+
+	return msg
+}
+
+func (e *PermissionError) Unwrap() error {
+	return fs.ErrPermission
+}
+
+var (
+	errIsDir  = errors.New("is a directory")
+	errNotDir = errors.New("not a directory")
+)
+
+func handleErr(err error, op string, path string) error {
+	if err == nil {
+		return nil
+	}
+
+	var rateLimitErr *github.RateLimitError
+	var abuseRateLimitErr *github.AbuseRateLimitError
+
+	if errors.As(err, &rateLimitErr) || errors.As(err, &abuseRateLimitErr) {
+		return &fs.PathError{Op: op, Path: path, Err: fmt.Errorf("%w: %w", ErrRateLimited, err)}
+	}
+
+	if gherr := (*github.ErrorResponse)(nil); errors.As(err, &gherr) {
+		switch gherr.Response.StatusCode {
+		case http.StatusNotFound:
+			return &fs.PathError{Op: op, Path: path, Err: fs.ErrNotExist}
+		case http.StatusNotModified:
+			return &fs.PathError{Op: op, Path: path, Err: ErrNotModified}
+		case http.StatusUnavailableForLegalReasons:
+			return &fs.PathError{Op: op, Path: path, Err: fmt.Errorf("%w: %w", ErrBlocked, err)}
+		case http.StatusForbidden, http.StatusUnauthorized:
+			if gherr.Block != nil {
+				return &fs.PathError{Op: op, Path: path, Err: fmt.Errorf("%w: %w", ErrBlocked, err)}
+			}
+
+			if hasErrorCode(gherr, "too_large") {
+				return &fs.PathError{Op: op, Path: path, Err: fmt.Errorf("%w: %w", ErrTooLarge, err)}
+			}
+
+			if isProtectedBranch(gherr) {
+				return &fs.PathError{Op: op, Path: path, Err: fmt.Errorf("%w: %w", ErrProtectedBranch, err)}
+			}
+
+			return &fs.PathError{Op: op, Path: path, Err: newPermissionError(gherr.Response)}
+		case http.StatusConflict, http.StatusUnprocessableEntity:
+			if isProtectedBranch(gherr) {
+				return &fs.PathError{Op: op, Path: path, Err: fmt.Errorf("%w: %w", ErrProtectedBranch, err)}
+			}
+
+			// Empty repositories are reported with a conflict as well.
+			if gherr.Response.StatusCode == http.StatusConflict && !strings.Contains(strings.ToLower(gherr.Message), "empty") {
+				return &fs.PathError{Op: op, Path: path, Err: fmt.Errorf("%w: %w", ErrModified, err)}
+			}
+		case http.StatusPreconditionFailed:
+			return &fs.PathError{Op: op, Path: path, Err: fmt.Errorf("%w: %w", ErrModified, err)}
+		}
+
+		return err
+	}
+
+	if errors.Is(err, ErrCredentialExpired) || errors.Is(err, ErrBudgetExceeded) {
+		return &fs.PathError{Op: op, Path: path, Err: err}
+	}
+
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+
+	if errors.As(err, &opErr) || errors.As(err, &dnsErr) {
+		return &fs.PathError{Op: op, Path: path, Err: fmt.Errorf("%w: %w", ErrOffline, err)}
+	}
+
+	return err
+}
+
+// isNotFound reports whether an error (returned by the GitHub client or already handled) means a resource does not exist.
+func isNotFound(err error) bool {
+	return errors.Is(handleErr(err, "", ""), fs.ErrNotExist)
+}
+
+// isProtectedBranch reports whether GitHub rejected a request due to branch protection or repository rules.
+func isProtectedBranch(err *github.ErrorResponse) bool {
+	message := strings.ToLower(err.Message)
+
+	return strings.Contains(message, "protected branch") || strings.Contains(message, "rule violation")
+}
+
+// isNotATree reports whether the Git Trees API rejected a tree-ish because it does not point to a tree (e.g. a file).
+func isNotATree(err error) bool {
+	gherr := (*github.ErrorResponse)(nil)
+
+	return errors.As(err, &gherr) && gherr.Response.StatusCode == http.StatusUnprocessableEntity
+}
+
+func hasErrorCode(err *github.ErrorResponse, code string) bool {
+	return slices.ContainsFunc(err.Errors, func(e github.Error) bool {
+		return e.Code == code
+	})
+}
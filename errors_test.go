@@ -0,0 +1,118 @@
+package githubfs
+
+import (
+	"errors"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v74/github"
+)
+
+func TestHandleErr(t *testing.T) {
+	response := func(code int) *http.Response {
+		return &http.Response{StatusCode: code, Request: &http.Request{Method: http.MethodGet, URL: &url.URL{}}}
+	}
+
+	testCases := []struct {
+		name   string
+		err    error
+		target error
+	}{
+		{"not found", &github.ErrorResponse{Response: response(http.StatusNotFound)}, fs.ErrNotExist},
+		{"forbidden", &github.ErrorResponse{Response: response(http.StatusForbidden)}, fs.ErrPermission},
+		{"unauthorized", &github.ErrorResponse{Response: response(http.StatusUnauthorized)}, fs.ErrPermission},
+		{"not modified", &github.ErrorResponse{Response: response(http.StatusNotModified)}, ErrNotModified},
+		{"rate limited", &github.RateLimitError{Response: response(http.StatusForbidden)}, ErrRateLimited},
+		{"secondary rate limited", &github.AbuseRateLimitError{Response: response(http.StatusForbidden)}, ErrRateLimited},
+		{"too large", &github.ErrorResponse{Response: response(http.StatusForbidden), Errors: []github.Error{{Code: "too_large"}}}, ErrTooLarge},
+		{"unavailable for legal reasons", &github.ErrorResponse{Response: response(http.StatusUnavailableForLegalReasons)}, ErrBlocked},
+		{"blocked", &github.ErrorResponse{Response: response(http.StatusForbidden), Block: &github.ErrorBlock{Reason: "dmca"}}, ErrBlocked},
+		{"offline", &url.Error{Op: "Get", URL: "https://api.github.com", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}, ErrOffline},
+		{"dns", &url.Error{Op: "Get", URL: "https://api.github.com", Err: &net.DNSError{Err: "no such host", IsNotFound: true}}, ErrOffline},
+		{"protected branch", &github.ErrorResponse{Response: response(http.StatusUnprocessableEntity), Message: "Protected branch update failed for refs/heads/main."}, ErrProtectedBranch},
+		{"repository rules", &github.ErrorResponse{Response: response(http.StatusConflict), Message: "Repository rule violations found"}, ErrProtectedBranch},
+		{"protected branch forbidden", &github.ErrorResponse{Response: response(http.StatusForbidden), Message: "Protected branch update failed"}, ErrProtectedBranch},
+		{"modified", &github.ErrorResponse{Response: response(http.StatusConflict), Message: "README.md does not match 3d21ec53a331a6f037a91c368710b99387d012c1"}, ErrModified},
+		{"precondition failed", &github.ErrorResponse{Response: response(http.StatusPreconditionFailed)}, ErrModified},
+		{"budget exceeded", &url.Error{Op: "Get", URL: "https://api.github.com", Err: ErrBudgetExceeded}, ErrBudgetExceeded},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := handleErr(tc.err, "open", "/owner/repo")
+
+			if !errors.Is(err, tc.target) {
+				t.Errorf("expected %v, got %v", tc.target, err)
+			}
+
+			var pathErr *fs.PathError
+			if !errors.As(err, &pathErr) {
+				t.Fatalf("expected *fs.PathError, got %T", err)
+			}
+
+			if pathErr.Op != "open" || pathErr.Path != "/owner/repo" {
+				t.Errorf("unexpected path error: %v", pathErr)
+			}
+		})
+	}
+
+	t.Run("preserves original error", func(t *testing.T) {
+		original := &github.RateLimitError{Response: response(http.StatusForbidden)}
+
+		var rateLimitErr *github.RateLimitError
+		if !errors.As(handleErr(original, "open", "/"), &rateLimitErr) {
+			t.Error("expected the original error to be preserved")
+		}
+	})
+
+	t.Run("permission details", func(t *testing.T) {
+		resp := response(http.StatusForbidden)
+		resp.Header = http.Header{
+			"X-Accepted-Oauth-Scopes": {"repo"},
+			"X-Oauth-Scopes":          {"read:org, gist"},
+			"X-Github-Sso":            {"required; url=https://github.com/orgs/acme/sso?authorization_request=abc"},
+		}
+
+		err := handleErr(&github.ErrorResponse{Response: resp}, "open", "/")
+
+		var permErr *PermissionError
+		if !errors.As(err, &permErr) {
+			t.Fatalf("expected *PermissionError, got %T", err)
+		}
+
+		if !errors.Is(err, fs.ErrPermission) {
+			t.Error("expected permission errors to be reported as fs.ErrPermission")
+		}
+
+		if !slices.Equal(permErr.AcceptedScopes, []string{"repo"}) || !slices.Equal(permErr.TokenScopes, []string{"read:org", "gist"}) {
+			t.Errorf("unexpected scopes: accepted %v, token %v", permErr.AcceptedScopes, permErr.TokenScopes)
+		}
+
+		if permErr.SSOOrganization != "acme" || permErr.SSOURL != "https://github.com/orgs/acme/sso?authorization_request=abc" {
+			t.Errorf("unexpected SSO details: %q at %q", permErr.SSOOrganization, permErr.SSOURL)
+		}
+
+		if msg := err.Error(); !strings.Contains(msg, "acme") || !strings.Contains(msg, "accepted scopes: repo") {
+			t.Errorf("expected details in the error message: %s", msg)
+		}
+	})
+
+	t.Run("empty repository", func(t *testing.T) {
+		err := handleErr(&github.ErrorResponse{Response: response(http.StatusConflict), Message: "Git Repository is empty."}, "open", "/")
+
+		if errors.Is(err, ErrModified) {
+			t.Error("expected empty repositories not to be reported as modified")
+		}
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		if err := handleErr(nil, "open", "/"); err != nil {
+			t.Errorf("expected nil, got %v", err)
+		}
+	})
+}
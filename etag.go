@@ -0,0 +1,105 @@
+package githubfs
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// etagBodyLimit is the maximum size of response bodies kept for revalidation.
+const etagBodyLimit = 1 << 20
+
+// etagCache holds responses to revalidate with conditional requests (see [WithConditionalRequests]).
+type etagCache struct {
+	mu        sync.Mutex
+	responses map[string]*etagResponse
+}
+
+type etagResponse struct {
+	etag   string
+	header http.Header
+	body   []byte
+}
+
+func newETagCache() *etagCache {
+	return &etagCache{
+		responses: make(map[string]*etagResponse),
+	}
+}
+
+// etagTransport revalidates responses it has seen before using If-None-Match,
+// and serves the cached response when the server reports that the resource has not changed.
+type etagTransport struct {
+	base  http.RoundTripper
+	cache *etagCache
+}
+
+func (t *etagTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" {
+		return t.base.RoundTrip(req)
+	}
+
+	key := req.URL.String() + " " + req.Header.Get("Accept")
+
+	t.cache.mu.Lock()
+	cached := t.cache.responses[key]
+	t.cache.mu.Unlock()
+
+	if cached != nil {
+		req = req.Clone(req.Context())
+		req.Header.Set("If-None-Match", cached.etag)
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		resp.Body.Close()
+
+		return &http.Response{
+			Status:        "200 OK",
+			StatusCode:    http.StatusOK,
+			Proto:         resp.Proto,
+			ProtoMajor:    resp.ProtoMajor,
+			ProtoMinor:    resp.ProtoMinor,
+			Header:        cached.header.Clone(),
+			Body:          io.NopCloser(bytes.NewReader(cached.body)),
+			ContentLength: int64(len(cached.body)),
+			Request:       req,
+		}, nil
+	}
+
+	etag := resp.Header.Get("ETag")
+	if resp.StatusCode != http.StatusOK || etag == "" {
+		return resp, nil
+	}
+
+	// Large responses are passed through without being cached.
+	body, err := io.ReadAll(io.LimitReader(resp.Body, etagBodyLimit+1))
+	if err != nil {
+		resp.Body.Close()
+
+		return nil, err
+	}
+
+	if len(body) > etagBodyLimit {
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+
+		return resp, nil
+	}
+
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	t.cache.mu.Lock()
+	t.cache.responses[key] = &etagResponse{etag: etag, header: resp.Header.Clone(), body: body}
+	t.cache.mu.Unlock()
+
+	return resp, nil
+}
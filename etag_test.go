@@ -0,0 +1,44 @@
+package githubfs
+
+import (
+	"io/fs"
+	"net/http"
+	"testing"
+)
+
+func TestWithConditionalRequests(t *testing.T) {
+	mux, opt := setup(t)
+
+	var requests, revalidated int
+
+	mux.HandleFunc("GET /repos/owner/repo/contents/README.md", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			revalidated++
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+
+		w.Header().Set("ETag", `"v1"`)
+		fileHandler("content")(w, r)
+	})
+
+	fsys := New(opt, WithRepository("owner", "repo"), WithConditionalRequests())
+
+	for range 3 {
+		content, err := fs.ReadFile(fsys, "README.md")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(content) != "content" {
+			t.Errorf("unexpected content: %q", content)
+		}
+	}
+
+	if requests != 3 || revalidated != 2 {
+		t.Errorf("expected repeated reads to be revalidated: %d requests, %d revalidated", requests, revalidated)
+	}
+}
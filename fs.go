@@ -4,155 +4,940 @@ package githubfs
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"io/fs"
 	"net/http"
+	"net/url"
 	"path"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/google/go-github/v74/github"
+	"golang.org/x/oauth2"
+	"golang.org/x/sync/singleflight"
 )
 
-// fsys implements fs.FS for GitHub repositories.
-type fsys struct {
+// FS implements [fs.FS] for GitHub repositories.
+type FS struct {
 	ref ref
 
-	ctx    context.Context
-	ctxFn  func(context.Context) context.Context
+	revision  string
+	at        time.Time
+	revisions *revisions
+
+	// calls deduplicates concurrent identical requests (shared between clones).
+	calls *singleflight.Group
+	cache *responseCache
+	etags *etagCache
+
+	// metadata caches directory listings (see [WithMetadataCache]).
+	metadata *responseCache
+
+	// notFound caches 404 responses (see [WithNegativeCache]).
+	notFound *responseCache
+
+	// modes caches modes of entries at branches briefly when [WithMetadataCache] is disabled (see [FS.dirModes]).
+	modes *responseCache
+
+	// times caches commit times at branches briefly when [WithMetadataCache] is disabled (see [FS.commitTime]).
+	times *responseCache
+
+	// limit bounds the number of requests in flight (see [WithMaxConcurrency]).
+	limit chan struct{}
+
+	budget *requestBudget
+
+	// timeout is the deadline of each request (see [WithTimeout]).
+	timeout time.Duration
+
+	retry *RetryPolicy
+
+	// memory caps content buffered in memory (see [WithMemoryLimit]).
+	memory *memoryLimit
+
+	// stats counts requests and cache lookups (shared between clones).
+	stats *stats
+
+	// snapshot seeds the caches when the filesystem is initialized (see [WithSnapshot]).
+	snapshot *snapshot
+
+	lfs bool
+
+	rawBackend bool
+	rawURL     *url.URL
+
+	skipInaccessible bool
+
+	perPage int
+
+	lineEnding LineEnding
+
+	commitModTimes bool
+
+	globStrategy GlobStrategy
+
+	eager    bool
+	archives bool
+	graphql  bool
+
+	ctx         context.Context
+	ctxFn       func(context.Context) context.Context
+	baseClient  *github.Client
+	baseURL     string
+	uploadURL   string
+	transport   *transportConfig
+	owners      map[string]*github.Client
+	userAgent   string
+	mediaType   string
+	verify      bool
+	tokenSource oauth2.TokenSource
+	tokenFunc   func(ctx context.Context) (string, error)
+	reauth      func(ctx context.Context) error
+
+	// client is the client built from baseClient and the configured options.
 	client *github.Client
+
+	// lfsClient downloads Git LFS objects from storage hosts (see [FS.buildLFSClient]).
+	lfsClient *http.Client
+
+	// err is a configuration error reported by every operation.
+	err error
+
+	// invalid holds invalid options reported by NewFS (New ignores them for compatibility).
+	invalid []error
+}
+
+// New creates a new GitHub filesystem for the specified repository.
+func New(opts ...Option) *FS {
+	f := &FS{
+		revisions: newRevisions(),
+		calls:     new(singleflight.Group),
+		stats:     newStats(),
+		times:     newResponseCache(branchTimesTTL),
+		modes:     newResponseCache(dirModesTTL),
+	}
+
+	for _, opt := range opts {
+		opt.apply(f)
+	}
+
+	f.init()
+
+	return f
+}
+
+// NewFS creates a new GitHub filesystem like [New], but reports invalid configurations upfront
+// instead of at the first operation.
+//
+// Unlike [New], which silently clamps out of range option values, NewFS rejects them
+// along with options that conflict with each other (e.g. multiple backends).
+func NewFS(opts ...Option) (*FS, error) {
+	f := New(opts...)
+
+	if err := f.validateOptions(); err != nil {
+		return nil, err
+	}
+
+	if f.verify {
+		if err := f.Validate(f.ctx).Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	return f, nil
+}
+
+// init sets defaults and builds the client after options are applied.
+func (f *FS) init() {
+	if f.ctx == nil {
+		f.ctx = context.Background()
+	}
+
+	if f.ctxFn == nil {
+		f.ctxFn = func(ctx context.Context) context.Context {
+			return ctx
+		}
+	}
+
+	if f.baseClient == nil {
+		f.baseClient = github.NewClient(nil)
+	}
+
+	if f.ref.owner == "" && f.ref.repo != "" {
+		f.err = errors.Join(f.err, fmt.Errorf("repository %q configured without an owner", f.ref.repo))
+		f.ref = ref{}
+	}
+
+	if f.baseURL != "" {
+		uploadURL := f.uploadURL
+		if uploadURL == "" {
+			uploadURL = f.baseURL
+		}
+
+		client, err := f.baseClient.WithEnterpriseURLs(f.baseURL, uploadURL)
+		if err != nil {
+			f.err = errors.Join(f.err, fmt.Errorf("invalid base URL: %w", err))
+		} else {
+			f.baseClient = client
+		}
+
+		// The base client is configured once: filesystems returned by SubWithOptions inherit it
+		f.baseURL, f.uploadURL = "", ""
+	}
+
+	if f.transport != nil {
+		client, err := configureTransport(f.baseClient, f.transport)
+		if err != nil {
+			f.err = errors.Join(f.err, err)
+		} else {
+			f.baseClient = client
+		}
+
+		f.transport = nil
+	}
+
+	if f.perPage == 0 {
+		f.perPage = 100
+	}
+
+	f.rawURL = rawBaseURL(f.baseClient.BaseURL)
+
+	f.client = f.buildClient()
+	f.lfsClient = f.buildLFSClient()
+
+	if f.snapshot != nil {
+		f.loadSnapshot(f.snapshot)
+		f.snapshot = nil
+	}
+}
+
+// buildClient wraps the base client according to the configured options.
+func (f *FS) buildClient() *github.Client {
+	client := f.baseClient
+
+	if len(f.owners) > 0 {
+		owners := make(map[string]http.RoundTripper, len(f.owners))
+		for owner, c := range f.owners {
+			transport := c.Client().Transport
+			if transport == nil {
+				transport = http.DefaultTransport
+			}
+
+			owners[owner] = transport
+		}
+
+		client = wrapClient(client, func(base http.RoundTripper) http.RoundTripper {
+			return &ownerTransport{base: base, owners: owners, baseURL: client.BaseURL}
+		})
+	}
+
+	if f.timeout > 0 {
+		client = wrapClient(client, func(base http.RoundTripper) http.RoundTripper {
+			return &timeoutTransport{base: base, timeout: f.timeout}
+		})
+	}
+
+	client = wrapClient(client, func(base http.RoundTripper) http.RoundTripper {
+		return &statsTransport{base: base, stats: f.stats, baseURL: client.BaseURL}
+	})
+
+	if f.budget != nil {
+		client = wrapClient(client, func(base http.RoundTripper) http.RoundTripper {
+			return &budgetTransport{base: base, budget: f.budget}
+		})
+	}
+
+	if f.retry != nil {
+		client = wrapClient(client, func(base http.RoundTripper) http.RoundTripper {
+			return &retryTransport{base: base, policy: f.retry.withDefaults()}
+		})
+	}
+
+	if f.userAgent != "" {
+		client = wrapClient(client, func(base http.RoundTripper) http.RoundTripper {
+			return &userAgentTransport{base: base, userAgent: f.userAgent}
+		})
+		client.UserAgent = f.userAgent
+	}
+
+	if f.etags != nil {
+		client = wrapClient(client, func(base http.RoundTripper) http.RoundTripper {
+			return &etagTransport{base: base, cache: f.etags}
+		})
+	}
+
+	var tokenSource *resettableTokenSource
+
+	if f.tokenSource != nil {
+		tokenSource = newResettableTokenSource(f.tokenSource)
+
+		client = wrapClient(client, func(base http.RoundTripper) http.RoundTripper {
+			return &hostTransport{base: base, auth: &oauth2.Transport{Source: tokenSource, Base: base}, host: client.BaseURL.Host}
+		})
+	}
+
+	if f.tokenFunc != nil {
+		client = wrapClient(client, func(base http.RoundTripper) http.RoundTripper {
+			return &hostTransport{base: base, auth: &tokenFuncTransport{base: base, fn: f.tokenFunc}, host: client.BaseURL.Host}
+		})
+	}
+
+	var reauth func(ctx context.Context) error
+
+	if tokenSource != nil || f.reauth != nil {
+		reauth = func(ctx context.Context) error {
+			if tokenSource != nil {
+				tokenSource.reset()
+			}
+
+			if f.reauth != nil {
+				return f.reauth(ctx)
+			}
+
+			return nil
+		}
+	}
+
+	client = wrapClient(client, func(base http.RoundTripper) http.RoundTripper {
+		return &credentialTransport{base: base, reauth: reauth, owner: (&ownerTransport{baseURL: client.BaseURL}).owner}
+	})
+
+	if f.limit != nil {
+		client = wrapClient(client, func(base http.RoundTripper) http.RoundTripper {
+			return &limitTransport{base: base, sem: f.limit}
+		})
+	}
+
+	return client
+}
+
+// Owner returns the owner the filesystem is rooted at (or an empty string if the filesystem lists owners).
+func (f *FS) Owner() string {
+	return f.ref.owner
+}
+
+// Repo returns the repository the filesystem is rooted at (or an empty string if the filesystem lists repositories).
+func (f *FS) Repo() string {
+	return f.ref.repo
+}
+
+// Ref returns the configured git reference (see [WithRef]).
+//
+// An empty string means the default branch of each repository.
+func (f *FS) Ref() string {
+	return f.revision
+}
+
+// Client returns the [github.Client] used by the filesystem (including the transports added by options).
+func (f *FS) Client() *github.Client {
+	return f.client
+}
+
+// RequestsUsed returns the number of requests counted against the budget configured by [WithRequestBudget].
+//
+// Returns 0 if no budget is configured.
+func (f *FS) RequestsUsed() int {
+	if f.budget == nil {
+		return 0
+	}
+
+	return int(f.budget.used.Load())
+}
+
+// clone creates a copy of the filesystem.
+func (f *FS) clone(r ref) *FS {
+	c := *f
+	c.ref = r
+
+	return &c
+}
+
+// Open implements the [fs.FS] interface.
+func (f *FS) Open(name string) (fs.File, error) {
+	return f.open(f.ctx, name)
+}
+
+// OpenContext is like [FS.Open], but uses ctx instead of the configured context.
+//
+// ctx is also used by requests made while reading the returned file:
+// reading fails with the context error once ctx is done.
+func (f *FS) OpenContext(ctx context.Context, name string) (fs.File, error) {
+	return f.open(ctx, name)
+}
+
+func (f *FS) open(ctx context.Context, name string) (fs.File, error) {
+	ref, err := f.resolve("open", name)
+	if err != nil {
+		return nil, err
+	}
+
+	if ref.repo == "" {
+		return f.listRepositories(ctx, ref.owner, listCursor{})
+	}
+
+	content, err := f.getRepoContent(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	if file, ok := content.(*file); ok {
+		file.ctx = ctx
+	}
+
+	return content, nil
 }
 
-// New creates a new GitHub filesystem for the specified repository.
-func New(opts ...Option) fs.FS {
-	f := &fsys{}
+// listRepositories lists repositories for a given owner, starting at a cursor.
+//
+// Only the first page is fetched upfront: further pages are fetched as entries are read.
+func (f *FS) listRepositories(ctx context.Context, owner string, start listCursor) (fs.File, error) {
+	if f.graphql {
+		return f.listRepositoriesGraphQL(ctx, owner, start)
+	}
+
+	opts := &github.RepositoryListByUserOptions{
+		Sort:        "full_name",
+		Direction:   "asc",
+		ListOptions: github.ListOptions{PerPage: f.perPage, Page: start.page},
+	}
+
+	d := &dir{
+		name: owner,
+	}
+
+	var pages listPages
+
+	d.more = func() ([]*dirEntry, bool, error) {
+		pages = append(pages, listCursor{page: max(opts.Page, 1), index: len(d.entries)})
+
+		repos, resp, err := f.client.Repositories.ListByUser(f.ctxFn(ctx), owner, opts)
+		if err := handleErr(err, "open", "/"+owner); err != nil {
+			return nil, false, err
+		}
+
+		entries := make([]*dirEntry, len(repos))
+		for i, repo := range repos {
+			pushedAt := repo.GetPushedAt()
+			if pushedAt.IsZero() {
+				pushedAt = repo.GetUpdatedAt()
+			}
+
+			entries[i] = &dirEntry{
+				name:    repo.GetName(),
+				isDir:   true,
+				size:    0,
+				modTime: fixedModTime(pushedAt.Time),
+				sys:     repo,
+			}
+		}
+
+		opts.Page = resp.NextPage
+
+		return entries, resp.NextPage != 0, nil
+	}
+
+	d.cursor = func() string {
+		if d.offset < len(d.entries) {
+			return pages.cursor(d.offset).encode()
+		}
+
+		if d.more != nil {
+			return listCursor{page: opts.Page}.encode()
+		}
+
+		return ""
+	}
+
+	// Fetch the first page to report errors when opening the directory
+	if err := d.fill(1); err != nil {
+		return nil, err
+	}
+
+	d.offset = min(start.index, len(d.entries))
+
+	return d, nil
+}
+
+// getRepoContent gets content from a specific repository
+func (f *FS) getRepoContent(ctx context.Context, r ref) (fs.File, error) {
+	revision, err := f.resolveRevision(ctx, r.owner, r.repo)
+	if err != nil {
+		return nil, err
+	}
+
+	if f.archives {
+		return f.openArchive(ctx, r, revision)
+	}
+
+	if f.eager {
+		idx, err := f.eagerTree(ctx, r.owner, r.repo, revision)
+		if err != nil {
+			return nil, err
+		}
+
+		p := r.path
+		if p == "" {
+			p = "."
+		}
+
+		if entries, ok := idx[p]; ok {
+			return &dir{
+				name:    path.Base(r.string()),
+				entries: slices.Clone(entries),
+				modTime: f.modTime(ctx, r, revision),
+			}, nil
+		}
+
+		if _, ok := idx.lookup(p); !ok {
+			return nil, &fs.PathError{Op: "open", Path: r.string(), Err: fs.ErrNotExist}
+		}
+	}
+
+	if f.graphql {
+		file, ok, err := f.openGraphQL(ctx, r, revision)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			return file, nil
+		}
+	}
+
+	if f.rawBackend && r.path != "" {
+		file, ok, err := f.openRawBackend(ctx, r, revision)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			return f.normalizeFile(file)
+		}
+	}
+
+	fileContent, dirContent, err := f.getContents(ctx, r, revision)
+	if err := handleErr(err, "open", r.string()); err != nil {
+		return nil, err
+	}
+
+	if fileContent != nil && f.mediaType != "" {
+		return f.openMediaType(ctx, r, revision, fileContent)
+	}
+
+	if fileContent != nil {
+		file, err := f.newFile(ctx, r, revision, fileContent)
+		if err != nil {
+			return nil, err
+		}
+
+		return f.normalizeFile(file)
+	}
+
+	if dirContent != nil {
+		if len(dirContent) >= contentsLimit {
+			dirContent, err = f.getTreeContents(ctx, "open", r, revision)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		modes := f.treeModes(ctx, r, revision)
+
+		entries := make([]*dirEntry, len(dirContent))
+		for i, content := range dirContent {
+			entries[i] = &dirEntry{
+				name:    content.GetName(),
+				isDir:   content.GetType() == "dir",
+				size:    int64(content.GetSize()),
+				modes:   modes,
+				modTime: f.modTime(ctx, r.join(content.GetName()), revision),
+				sys:     content,
+			}
+		}
+
+		sortEntries(entries)
+
+		return &dir{
+			name:    path.Base(r.string()),
+			entries: entries,
+			modTime: f.modTime(ctx, r, revision),
+		}, nil
+	}
+
+	return nil, errors.New("invalid response: no file or directory returned")
+}
+
+// contentsResponse is a response of the Contents API.
+type contentsResponse struct {
+	file *github.RepositoryContent
+	dir  []*github.RepositoryContent
+}
+
+// getContents requests the file or directory r points to using the Contents API.
+//
+// Concurrent identical requests share a single API call (and the context of the first caller).
+// Responses are cached under the same key for every operation,
+// so that [FS.Stat] is answered from listings fetched by [FS.ReadDir] (and vice versa).
+func (f *FS) getContents(ctx context.Context, r ref, revision string) (*github.RepositoryContent, []*github.RepositoryContent, error) {
+	key := contentsKey(r, revision)
+
+	if err, ok := f.notFound.get(key); ok {
+		f.recordCacheLookup(true, f.notFound)
+
+		return nil, nil, err.(error)
+	}
+
+	v, ok := f.metadata.get(key)
+	if !ok {
+		v, ok = f.cache.get(key)
+	}
+
+	f.recordCacheLookup(ok, f.notFound, f.metadata, f.cache)
+
+	if !ok {
+		var err error
+
+		v, err, _ = f.calls.Do(key, func() (any, error) {
+			fileContent, dirContent, _, err := f.client.Repositories.GetContents(f.ctxFn(ctx), r.owner, r.repo, r.path, &github.RepositoryContentGetOptions{Ref: revision})
+			if err != nil {
+				if isNotFound(err) {
+					f.notFound.set(key, err, isCommitSHA(revision))
+				}
+
+				return nil, err
+			}
+
+			c := contentsResponse{file: fileContent, dir: dirContent}
+			f.cache.set(key, c, isCommitSHA(revision))
+
+			// Directory listings are metadata: file responses carry content.
+			if dirContent != nil {
+				f.metadata.set(key, c, isCommitSHA(revision))
+			}
+
+			return c, nil
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	c := v.(contentsResponse)
+
+	return c.file, c.dir, nil
+}
+
+// contentsKey returns the cache key of a Contents API response.
+func contentsKey(r ref, revision string) string {
+	return "contents " + r.string() + "@" + revision
+}
+
+// ReadDir implements the [fs.ReadDirFS] interface.
+func (f *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return f.ReadDirContext(f.ctx, name)
+}
+
+// ReadDirContext is like [FS.ReadDir], but uses ctx instead of the configured context.
+func (f *FS) ReadDirContext(ctx context.Context, name string) ([]fs.DirEntry, error) {
+	entries, err := f.readDir(ctx, name)
+	if err != nil && f.skipInaccessible && (errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrPermission)) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.Join(fs.SkipDir, err)}
+	}
+
+	return entries, err
+}
+
+func (f *FS) readDir(ctx context.Context, name string) ([]fs.DirEntry, error) {
+	file, err := f.open(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	dir, ok := file.(fs.ReadDirFile)
+	if !ok {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errNotDir}
+	}
+
+	return dir.ReadDir(-1)
+}
+
+// ReadFile implements the [fs.ReadFileFS] interface.
+//
+// The content is fetched with a single request using the raw media type.
+func (f *FS) ReadFile(name string) ([]byte, error) {
+	return f.readFile(f.ctx, name)
+}
+
+// ReadFileContext is like [FS.ReadFile], but uses ctx instead of the configured context.
+func (f *FS) ReadFileContext(ctx context.Context, name string) ([]byte, error) {
+	return f.readFile(ctx, name)
+}
+
+func (f *FS) readFile(ctx context.Context, name string) ([]byte, error) {
+	r, err := f.resolve("read", name)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.repo == "" || r.path == "" {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: errIsDir}
+	}
+
+	revision, err := f.resolveRevision(ctx, r.owner, r.repo)
+	if err != nil {
+		return nil, err
+	}
+
+	if f.archives {
+		file, err := f.openArchive(ctx, r, revision)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		if _, ok := file.(*dir); ok {
+			return nil, &fs.PathError{Op: "read", Path: name, Err: errIsDir}
+		}
+
+		return io.ReadAll(file)
+	}
+
+	if f.graphql {
+		if content, ok := f.cachedBlob(r, revision); ok {
+			return f.normalize(content), nil
+		}
+	}
+
+	if f.rawBackend {
+		file, ok, err := f.openRawBackend(ctx, r, revision)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			defer file.Close()
+
+			content, err := io.ReadAll(file)
+			if err != nil {
+				return nil, err
+			}
+
+			return f.normalize(content), nil
+		}
+	}
+
+	if f.mediaType != "" {
+		file, err := f.getRepoContent(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		if _, ok := file.(*dir); ok {
+			return nil, &fs.PathError{Op: "read", Path: name, Err: errIsDir}
+		}
+
+		return io.ReadAll(file)
+	}
+
+	content, err := f.getRaw(ctx, r, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	if f.lfs {
+		if pointer, ok := parseLFSPointer(string(content)); ok {
+			object, err := f.openLFSObject(ctx, r, pointer)
+			if err != nil {
+				return nil, err
+			}
+			defer object.Close()
+
+			content, err := io.ReadAll(object)
+			if err != nil {
+				return nil, err
+			}
+
+			return f.normalize(content), nil
+		}
+	}
+
+	return f.normalize(content), nil
+}
+
+// getRaw requests the content of a file using the raw media type.
+//
+// Concurrent identical requests share a single API call (and the context of the first caller).
+func (f *FS) getRaw(ctx context.Context, r ref, revision string) ([]byte, error) {
+	key := "read " + r.string() + "@" + revision
+
+	// Content is shared between callers: return copies, so that callers can modify it
+	if v, ok := f.cache.get(key); ok {
+		f.recordCacheLookup(true, f.cache)
+
+		return slices.Clone(v.([]byte)), nil
+	}
+
+	if err, ok := f.notFound.get(key); ok {
+		f.recordCacheLookup(true, f.notFound)
 
-	for _, opt := range opts {
-		opt.apply(f)
+		return nil, err.(error)
 	}
 
-	if f.ctx == nil {
-		f.ctx = context.Background()
+	if sha := f.knownSHA(r, revision); sha != "" && f.cache != nil {
+		return f.getBlob(ctx, r, sha)
 	}
 
-	if f.ctxFn == nil {
-		f.ctxFn = func(ctx context.Context) context.Context {
-			return ctx
+	f.recordCacheLookup(false, f.cache, f.notFound)
+
+	v, err, _ := f.calls.Do(key, func() (any, error) {
+		resp, err := f.openRaw(ctx, r, revision, nil)
+		if err != nil {
+			if isNotFound(err) {
+				f.notFound.set(key, err, isCommitSHA(revision))
+			}
+
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		content, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+
+		if isDirListing(resp, content) {
+			return nil, &fs.PathError{Op: "read", Path: r.string(), Err: errIsDir}
+		}
+
+		// Content beyond the memory limit is not kept around
+		if f.memory.allows(len(content)) {
+			f.cache.set(key, content, isCommitSHA(revision))
+			f.cache.set(blobKey(blobHash(content)), content, false)
 		}
-	}
 
-	if f.client == nil {
-		f.client = github.NewClient(nil)
+		return content, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return f
+	return slices.Clone(v.([]byte)), nil
 }
 
-// clone creates a copy of the filesystem.
-func (f *fsys) clone(r ref) *fsys {
-	return &fsys{
-		ref:    r,
-		ctx:    f.ctx,
-		ctxFn:  f.ctxFn,
-		client: f.client,
-	}
+// Stat implements the [fs.StatFS] interface.
+//
+// Only metadata is requested: files and directories are looked up in the listing of their parent directory.
+func (f *FS) Stat(name string) (fs.FileInfo, error) {
+	return f.stat(f.ctx, name)
 }
 
-// Open implements the [fs.FS] interface.
-func (f *fsys) Open(name string) (fs.File, error) {
-	if !fs.ValidPath(name) {
-		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+// StatContext is like [FS.Stat], but uses ctx instead of the configured context.
+//
+// ctx is also used by requests made when the mode or modification time of the result is requested.
+func (f *FS) StatContext(ctx context.Context, name string) (fs.FileInfo, error) {
+	return f.stat(ctx, name)
+}
+
+func (f *FS) stat(ctx context.Context, name string) (fs.FileInfo, error) {
+	r, err := f.resolve("stat", name)
+	if err != nil {
+		return nil, err
 	}
 
-	ref := f.ref.join(name)
+	if r.repo == "" {
+		user, _, err := f.client.Users.Get(f.ctxFn(ctx), r.owner)
+		if err := handleErr(err, "stat", r.string()); err != nil {
+			return nil, err
+		}
 
-	if err := ref.validate("open"); err != nil {
-		return nil, err
+		return &fileInfo{name: r.owner, isDir: true, sys: user}, nil
 	}
 
-	if ref.repo == "" {
-		return f.listRepositories(ref.owner)
+	revision, err := f.resolveRevision(ctx, r.owner, r.repo)
+	if err != nil {
+		return nil, err
 	}
 
-	return f.getRepoContent(ref)
-}
+	if r.path == "" {
+		repo, _, err := f.client.Repositories.Get(f.ctxFn(ctx), r.owner, r.repo)
+		if err := handleErr(err, "stat", r.string()); err != nil {
+			return nil, err
+		}
 
-// listRepositories lists repositories for a given owner
-func (f *fsys) listRepositories(owner string) (fs.File, error) {
-	opts := &github.RepositoryListByUserOptions{
-		ListOptions: github.ListOptions{PerPage: 100},
+		return &fileInfo{name: r.repo, isDir: true, modTime: f.modTime(ctx, r, revision), sys: repo}, nil
 	}
 
-	var allRepos []*github.Repository
-	for {
-		repos, resp, err := f.client.Repositories.ListByUser(f.ctxFn(f.ctx), owner, opts)
-		if err := handleErr(err, "open", "/"+owner); err != nil {
+	if f.archives {
+		a, err := f.archive(ctx, r.owner, r.repo, revision)
+		if err != nil {
 			return nil, err
 		}
 
-		allRepos = append(allRepos, repos...)
-		if resp.NextPage == 0 {
-			break
+		entry, ok := a.index.lookup(r.path)
+		if !ok {
+			return nil, &fs.PathError{Op: "stat", Path: r.string(), Err: fs.ErrNotExist}
 		}
-		opts.Page = resp.NextPage
+
+		return entry.Info()
 	}
 
-	entries := make([]*dirEntry, len(allRepos))
-	for i, repo := range allRepos {
-		entries[i] = &dirEntry{
-			name:  repo.GetName(),
-			isDir: true,
-			size:  0,
+	if f.eager {
+		idx, err := f.eagerTree(ctx, r.owner, r.repo, revision)
+		if err != nil {
+			return nil, err
+		}
+
+		entry, ok := idx.lookup(r.path)
+		if !ok {
+			return nil, &fs.PathError{Op: "stat", Path: r.string(), Err: fs.ErrNotExist}
 		}
+
+		return entry.Info()
 	}
 
-	return &dir{
-		name:    owner,
-		entries: entries,
-	}, nil
-}
+	parent := r.parent()
 
-// getRepoContent gets content from a specific repository
-func (f *fsys) getRepoContent(r ref) (fs.File, error) {
-	fileContent, dirContent, _, err := f.client.Repositories.GetContents(f.ctxFn(f.ctx), r.owner, r.repo, r.path, &github.RepositoryContentGetOptions{})
-	if err := handleErr(err, "open", r.string()); err != nil {
+	_, dirContent, err := f.getContents(ctx, parent, revision)
+	if err := handleErr(err, "stat", r.string()); err != nil {
 		return nil, err
 	}
 
-	if fileContent != nil {
-		content, err := fileContent.GetContent()
+	if len(dirContent) >= contentsLimit {
+		dirContent, err = f.getTreeContents(ctx, "stat", parent, revision)
 		if err != nil {
 			return nil, err
 		}
-
-		return &file{
-			name:    fileContent.GetName(),
-			size:    int64(fileContent.GetSize()),
-			content: io.NopCloser(strings.NewReader(content)),
-		}, nil
 	}
 
-	if dirContent != nil {
-		entries := make([]*dirEntry, len(dirContent))
-		for i, content := range dirContent {
-			entries[i] = &dirEntry{
-				name:  content.GetName(),
-				isDir: content.GetType() == "dir",
-				size:  int64(content.GetSize()),
-			}
-		}
+	base := path.Base(r.path)
 
-		return &dir{
-			name:    path.Base(r.string()),
-			entries: entries,
-		}, nil
+	for _, content := range dirContent {
+		if content.GetName() == base {
+			return f.contentInfo(ctx, r, revision, content), nil
+		}
 	}
 
-	return nil, errors.New("invalid response: no file or directory returned")
+	return nil, &fs.PathError{Op: "stat", Path: r.string(), Err: fs.ErrNotExist}
+}
+
+// contentInfo returns the file info of the entry r points to from the listing of its parent directory.
+func (f *FS) contentInfo(ctx context.Context, r ref, revision string, content *github.RepositoryContent) *fileInfo {
+	return &fileInfo{
+		name:    content.GetName(),
+		size:    int64(content.GetSize()),
+		isDir:   content.GetType() == "dir",
+		modes:   f.treeModes(ctx, r.parent(), revision),
+		modTime: f.modTime(ctx, r, revision),
+		sys:     content,
+	}
 }
 
 // Sub implements the [fs.SubFS] interface.
-func (f *fsys) Sub(dir string) (fs.FS, error) {
+func (f *FS) Sub(dir string) (fs.FS, error) {
 	if !fs.ValidPath(dir) {
 		return nil, &fs.PathError{Op: "sub", Path: dir, Err: fs.ErrInvalid}
 	}
@@ -160,46 +945,371 @@ func (f *fsys) Sub(dir string) (fs.FS, error) {
 	return f.clone(f.ref.join(dir)), nil
 }
 
+// SubWithOptions returns a filesystem corresponding to the subtree rooted at dir (like [FS.Sub])
+// with additional options applied to it.
+//
+// The returned filesystem shares the configuration of the parent unless overridden by opts.
+func (f *FS) SubWithOptions(dir string, opts ...Option) (*FS, error) {
+	if !fs.ValidPath(dir) {
+		return nil, &fs.PathError{Op: "sub", Path: dir, Err: fs.ErrInvalid}
+	}
+
+	sub := f.clone(f.ref.join(dir))
+
+	for _, opt := range opts {
+		opt.apply(sub)
+	}
+
+	sub.init()
+
+	return sub, nil
+}
+
 var (
-	_ fs.FS    = (*fsys)(nil)
-	_ fs.SubFS = (*fsys)(nil)
-	_ fs.File  = (*file)(nil)
+	_ fs.FS         = (*FS)(nil)
+	_ fs.GlobFS     = (*FS)(nil)
+	_ fs.ReadDirFS  = (*FS)(nil)
+	_ fs.ReadFileFS = (*FS)(nil)
+	_ fs.StatFS     = (*FS)(nil)
+	_ fs.SubFS      = (*FS)(nil)
+	_ fs.File       = (*file)(nil)
+	_ io.Seeker     = (*file)(nil)
+	_ io.ReaderAt   = (*file)(nil)
+	_ io.WriterTo   = (*file)(nil)
+	_ FileMetadata  = (*file)(nil)
 )
 
 type file struct {
 	name    string
 	size    int64
+	modes   *treeModes
+	modTime *modTime
+	sys     any
 	content io.ReadCloser
+
+	// ctx is the context the file was opened with: reads fail once it is done.
+	ctx context.Context
+
+	// metadata (see FileMetadata)
+	sha         string
+	htmlURL     string
+	downloadURL string
+	revision    string
+
+	// offset is the current read position in content.
+	offset int64
+
+	// reopen fetches content from the start again (when it is streamed and cannot seek).
+	reopen func() (io.ReadCloser, error)
+
+	// openRange fetches n bytes of content starting at off (when the content host supports Range requests).
+	openRange func(off int64, n int64) (io.ReadCloser, error)
+}
+
+// open (re)opens content using reopen.
+func (f *file) open() error {
+	content, err := f.reopen()
+	if err != nil {
+		return err
+	}
+
+	f.content = content
+	f.offset = 0
+
+	return nil
 }
 
 func (f *file) Stat() (fs.FileInfo, error) {
 	return &fileInfo{
-		name:  f.name,
-		size:  f.size,
-		isDir: false,
+		name:    f.name,
+		size:    f.size,
+		isDir:   false,
+		modes:   f.modes,
+		modTime: f.modTime,
+		sys:     f.sys,
 	}, nil
 }
 
 func (f *file) Read(p []byte) (int, error) {
-	return f.content.Read(p)
+	if err := f.ctxErr(nil); err != nil {
+		return 0, err
+	}
+
+	n, err := f.content.Read(p)
+	f.offset += int64(n)
+
+	if err != nil {
+		err = f.ctxErr(err)
+	}
+
+	return n, err
+}
+
+// ctxErr returns the error of the context the file was opened with if it is done (and err otherwise),
+// so that reads aborted by a cancelled download report the cancellation instead of a transport error.
+func (f *file) ctxErr(err error) error {
+	if err == io.EOF || f.ctx == nil || f.ctx.Err() == nil {
+		return err
+	}
+
+	return f.ctx.Err()
+}
+
+// Seek implements the [io.Seeker] interface.
+//
+// Streamed content is fetched again from the new offset using a Range request if the content host supports it,
+// otherwise it is fetched again from the start when seeking backwards.
+func (f *file) Seek(offset int64, whence int) (int64, error) {
+	if seeker, ok := f.content.(io.Seeker); ok {
+		pos, err := seeker.Seek(offset, whence)
+		if err == nil {
+			f.offset = pos
+		}
+
+		return pos, err
+	}
+
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += f.size
+	default:
+		return 0, &fs.PathError{Op: "seek", Path: f.name, Err: fs.ErrInvalid}
+	}
+
+	if offset < 0 {
+		return 0, &fs.PathError{Op: "seek", Path: f.name, Err: fs.ErrInvalid}
+	}
+
+	// Seeking to (or past) the end does not need any content: the next read fetches content again if needed.
+	if offset >= f.size && offset != f.offset {
+		f.content.Close()
+		f.content = http.NoBody
+		f.offset = offset
+
+		return offset, nil
+	}
+
+	// Fetch the rest of the content from the new offset instead of reading up to it (if the content host supports it).
+	if f.openRange != nil && offset != f.offset && offset < f.size {
+		content, err := f.openRange(offset, f.size-offset)
+		if err != nil {
+			return 0, err
+		}
+
+		f.content.Close()
+		f.content = content
+		f.offset = offset
+
+		return offset, nil
+	}
+
+	if offset < f.offset {
+		f.content.Close()
+
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	if _, err := io.CopyN(io.Discard, f, offset-f.offset); err != nil && err != io.EOF {
+		return 0, err
+	}
+
+	// Seeking past the end is allowed.
+	f.offset = offset
+
+	return offset, nil
+}
+
+// ReadAt implements the [io.ReaderAt] interface.
+//
+// Content held in memory is read directly,
+// streamed content is fetched using Range requests where possible (or from the start otherwise).
+// The read position of the file is not affected.
+func (f *file) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, &fs.PathError{Op: "read", Path: f.name, Err: fs.ErrInvalid}
+	}
+
+	if content, ok := f.content.(nopSeekCloser); ok {
+		if readerAt, ok := content.ReadSeeker.(io.ReaderAt); ok {
+			return readerAt.ReadAt(p, off)
+		}
+	}
+
+	if content, ok := f.content.(*spooled); ok {
+		return content.ReadAt(p, off)
+	}
+
+	if off >= f.size {
+		return 0, io.EOF
+	}
+
+	n := min(int64(len(p)), f.size-off)
+
+	var content io.ReadCloser
+
+	switch {
+	case f.openRange != nil:
+		c, err := f.openRange(off, n)
+		if err != nil {
+			return 0, err
+		}
+
+		content = c
+
+	case f.reopen != nil:
+		c, err := f.reopen()
+		if err != nil {
+			return 0, err
+		}
+
+		content = c
+
+		if _, err := io.CopyN(io.Discard, content, off); err != nil {
+			content.Close()
+
+			return 0, err
+		}
+
+	default:
+		return 0, &fs.PathError{Op: "read", Path: f.name, Err: errors.ErrUnsupported}
+	}
+	defer content.Close()
+
+	read, err := io.ReadFull(content, p[:n])
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+
+	if err == nil && read < len(p) {
+		err = io.EOF
+	}
+
+	return read, err
+}
+
+// WriteTo implements the [io.WriterTo] interface.
+//
+// The remaining content is streamed to w directly, so [io.Copy] does not go through Read in small chunks.
+func (f *file) WriteTo(w io.Writer) (int64, error) {
+	if err := f.ctxErr(nil); err != nil {
+		return 0, err
+	}
+
+	var content io.Reader = f.content
+
+	// Unwrap in-memory content so that io.Copy can use its WriteTo method
+	if c, ok := f.content.(nopSeekCloser); ok {
+		content = c.ReadSeeker
+	}
+
+	n, err := io.Copy(w, content)
+	f.offset += n
+
+	if err != nil {
+		err = f.ctxErr(err)
+	}
+
+	return n, err
+}
+
+// FileMetadata provides GitHub specific metadata of files opened from the filesystem.
+//
+// Values are empty if they are not known (e.g. files served by the raw content host do not have an SHA).
+type FileMetadata interface {
+	// SHA returns the SHA of the git blob.
+	SHA() string
+
+	// HTMLURL returns the URL of the file on GitHub.
+	HTMLURL() string
+
+	// DownloadURL returns the URL the raw content of the file can be downloaded from.
+	DownloadURL() string
+
+	// Ref returns the git reference the file was read at.
+	//
+	// An empty string means the default branch of the repository.
+	Ref() string
+}
+
+func (f *file) SHA() string {
+	return f.sha
+}
+
+func (f *file) HTMLURL() string {
+	return f.htmlURL
+}
+
+func (f *file) DownloadURL() string {
+	return f.downloadURL
+}
+
+func (f *file) Ref() string {
+	return f.revision
 }
 
 func (f *file) Close() error {
 	return f.content.Close()
 }
 
-var _ fs.ReadDirFile = (*dir)(nil)
+// nopSeekCloser is content held in memory.
+type nopSeekCloser struct {
+	io.ReadSeeker
+}
+
+func (nopSeekCloser) Close() error {
+	return nil
+}
+
+var (
+	_ fs.ReadDirFile = (*dir)(nil)
+	_ io.Seeker      = (*dir)(nil)
+	_ CursorDir      = (*dir)(nil)
+)
 
 type dir struct {
 	name    string
 	entries []*dirEntry
+	modTime *modTime
 	offset  int // tracks the current reading position
+
+	// more fetches the next page of entries and reports whether there are more pages (nil once all pages are fetched).
+	//
+	// Entries of paginated directories are returned in API order by ReadDir(n > 0)
+	// and sorted when all remaining entries are read at once.
+	more func() ([]*dirEntry, bool, error)
+
+	// cursor returns the cursor of the next entry to read (for paginated directories).
+	cursor func() string
+}
+
+// fill fetches pages until at least n entries are available for reading (or all of them if n <= 0).
+func (d *dir) fill(n int) error {
+	for d.more != nil && (n <= 0 || len(d.entries)-d.offset < n) {
+		entries, more, err := d.more()
+		if err != nil {
+			return err
+		}
+
+		d.entries = append(d.entries, entries...)
+
+		if !more {
+			d.more = nil
+		}
+	}
+
+	return nil
 }
 
 func (d *dir) Stat() (fs.FileInfo, error) {
 	return &fileInfo{
-		name:  d.name,
-		isDir: true,
+		name:    d.name,
+		isDir:   true,
+		modTime: d.modTime,
 	}, nil
 }
 
@@ -207,12 +1317,40 @@ func (d *dir) Read(p []byte) (int, error) {
 	return 0, io.EOF
 }
 
+// Cursor implements the [CursorDir] interface.
+func (d *dir) Cursor() string {
+	if d.cursor == nil {
+		return ""
+	}
+
+	return d.cursor()
+}
+
+// Seek implements the [io.Seeker] interface.
+//
+// Only rewinding (Seek(0, io.SeekStart)) is supported: entries are read again from the beginning without requesting them again.
+func (d *dir) Seek(offset int64, whence int) (int64, error) {
+	if offset != 0 || whence != io.SeekStart {
+		return 0, &fs.PathError{Op: "seek", Path: d.name, Err: fs.ErrInvalid}
+	}
+
+	d.offset = 0
+
+	return 0, nil
+}
+
 func (d *dir) Close() error {
 	return nil
 }
 
 func (d *dir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if err := d.fill(n); err != nil {
+		return nil, err
+	}
+
 	if n <= 0 {
+		sortEntries(d.entries[d.offset:])
+
 		// Return all remaining entries from current offset
 		remaining := len(d.entries) - d.offset
 		if remaining == 0 {
@@ -252,12 +1390,23 @@ func (d *dir) ReadDir(n int) ([]fs.DirEntry, error) {
 	return entries, nil
 }
 
+// sortEntries sorts directory entries by name (as [os.ReadDir] does),
+// since the API does not guarantee any order.
+func sortEntries(entries []*dirEntry) {
+	slices.SortFunc(entries, func(a, b *dirEntry) int {
+		return strings.Compare(a.name, b.name)
+	})
+}
+
 var _ fs.FileInfo = (*fileInfo)(nil)
 
 type fileInfo struct {
-	name  string
-	size  int64
-	isDir bool
+	name    string
+	size    int64
+	isDir   bool
+	modes   *treeModes
+	modTime *modTime
+	sys     any
 }
 
 func (fi *fileInfo) Name() string {
@@ -273,27 +1422,40 @@ func (fi *fileInfo) Mode() fs.FileMode {
 		return fs.ModeDir | 0o755
 	}
 
-	return 0o644
+	if isSymlink(fi.sys) {
+		return fs.ModeSymlink | 0o777
+	}
+
+	return fi.modes.mode(fi.name)
 }
 
+// ModTime returns the date of the commit the repository is read at (or the zero time outside repositories).
 func (fi *fileInfo) ModTime() time.Time {
-	return time.Time{}
+	return fi.modTime.time()
 }
 
 func (fi *fileInfo) IsDir() bool {
 	return fi.isDir
 }
 
+// Sys returns the underlying GitHub object (if available):
+//   - [*github.RepositoryContent] for files and directories
+//   - [*github.TreeEntry] for entries visited by [Walk]
+//   - [*github.Repository] for repositories
+//   - [*github.User] for owners
 func (fi *fileInfo) Sys() any {
-	return nil
+	return fi.sys
 }
 
 var _ fs.DirEntry = (*dirEntry)(nil)
 
 type dirEntry struct {
-	name  string
-	isDir bool
-	size  int64
+	name    string
+	isDir   bool
+	size    int64
+	modes   *treeModes
+	modTime *modTime
+	sys     any
 }
 
 func (e *dirEntry) Name() string {
@@ -308,17 +1470,59 @@ func (e *dirEntry) Type() fs.FileMode {
 	if e.isDir {
 		return fs.ModeDir
 	}
+
+	if isSymlink(e.sys) {
+		return fs.ModeSymlink
+	}
+
 	return 0
 }
 
+// isSymlink reports whether the underlying GitHub object of an entry is a symbolic link,
+// so its type is known without fetching modes.
+func isSymlink(sys any) bool {
+	switch sys := sys.(type) {
+	case *github.RepositoryContent:
+		return sys.GetType() == "symlink"
+	case *github.TreeEntry:
+		return sys.GetMode() == "120000"
+	default:
+		return false
+	}
+}
+
 func (e *dirEntry) Info() (fs.FileInfo, error) {
 	return &fileInfo{
-		name:  e.name,
-		size:  e.size,
-		isDir: e.isDir,
+		name:    e.name,
+		size:    e.size,
+		isDir:   e.isDir,
+		modes:   e.modes,
+		modTime: e.modTime,
+		sys:     e.sys,
 	}, nil
 }
 
+// resolve validates a name passed to an entry point of the filesystem and joins it to the root of the filesystem.
+//
+// Entry points taking names must go through resolve, so that names are parsed the same way everywhere.
+func (f *FS) resolve(op string, name string) (ref, error) {
+	if f.err != nil {
+		return ref{}, &fs.PathError{Op: op, Path: name, Err: f.err}
+	}
+
+	if !fs.ValidPath(name) {
+		return ref{}, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+
+	r := f.ref.join(name)
+
+	if err := r.validate(op); err != nil {
+		return ref{}, err
+	}
+
+	return r, nil
+}
+
 type ref struct {
 	owner string
 	repo  string
@@ -328,6 +1532,9 @@ type ref struct {
 func (r ref) join(name string) ref {
 	if r.owner != "" && r.repo != "" {
 		r.path = path.Join(r.path, name)
+		if r.path == "." {
+			r.path = ""
+		}
 
 		return r
 	}
@@ -372,22 +1579,16 @@ func (r ref) validate(op string) error {
 	return nil
 }
 
-func (r ref) string() string {
-	return path.Join("/", r.owner, r.repo, r.path)
-}
-
-func handleErr(err error, op string, path string) error {
-	if gherr := (*github.ErrorResponse)(nil); errors.As(err, &gherr) {
-		switch gherr.Response.StatusCode {
-		case http.StatusNotFound:
-			return &fs.PathError{Op: op, Path: path, Err: fs.ErrNotExist}
-		case http.StatusForbidden, http.StatusUnauthorized:
-			return &fs.PathError{Op: op, Path: path, Err: fs.ErrPermission}
-		}
-		return err
-	} else if err != nil {
-		return err
+// parent returns the ref of the directory containing the path.
+func (r ref) parent() ref {
+	r.path = path.Dir(r.path)
+	if r.path == "." {
+		r.path = ""
 	}
 
-	return nil
+	return r
+}
+
+func (r ref) string() string {
+	return path.Join("/", r.owner, r.repo, r.path)
 }
@@ -6,6 +6,7 @@ import (
 	"os"
 
 	"github.com/google/go-github/v74/github"
+	"golang.org/x/oauth2"
 
 	githubfs "github.com/sagikazarmark/go-github-fs"
 )
@@ -33,3 +34,17 @@ func Example() {
 	// Output:
 	// # Finder library for [Afero](https://github.com/spf13/afero)
 }
+
+func ExampleWithTokenSource() {
+	// Any token source works: tokens are refreshed when they expire
+	// (e.g. GitHub App installation tokens or tokens exchanged for workload identities).
+	ts := oauth2.ReuseTokenSource(nil, oauth2.StaticTokenSource(&oauth2.Token{AccessToken: os.Getenv("GITHUB_TOKEN")}))
+
+	fsys := githubfs.New(githubfs.WithTokenSource(ts), githubfs.WithRepository("sagikazarmark", "locafero"))
+
+	file, err := fsys.Open("README.md")
+	if err != nil {
+		panic(err)
+	}
+	defer file.Close()
+}
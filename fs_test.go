@@ -1,14 +1,27 @@
 package githubfs
 
 import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"path"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"testing/fstest"
+	"testing/iotest"
+	"time"
 
 	"github.com/google/go-github/v74/github"
 )
@@ -533,3 +546,1088 @@ func mustFS(t *testing.T) func(fsys fs.FS, err error) fs.FS {
 		return fsys
 	}
 }
+
+// setup starts a fake GitHub API server and returns its mux along with an option configuring a client for it.
+func setup(t *testing.T) (*http.ServeMux, Option) {
+	t.Helper()
+
+	mux := http.NewServeMux()
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	return mux, WithClient(client)
+}
+
+func TestLargeFile(t *testing.T) {
+	mux, opt := setup(t)
+
+	mux.HandleFunc("GET /repos/owner/repo/contents/large.bin", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") == mediaTypeRaw {
+			w.Write([]byte("content"))
+
+			return
+		}
+
+		w.Write([]byte(`{"type":"file","name":"large.bin","encoding":"none","content":"","sha":"abc","size":7}`))
+	})
+
+	content, err := fs.ReadFile(New(opt, WithRepository("owner", "repo")), "large.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(content) != "content" {
+		t.Errorf("unexpected content: %q", content)
+	}
+}
+
+func TestSkipInaccessible(t *testing.T) {
+	mux, opt := setup(t)
+
+	mux.HandleFunc("GET /repos/owner/repo", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"name":"repo"}`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/contents/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"type":"dir","name":"private"},{"type":"dir","name":"public"}]`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/contents/private", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"message":"Resource not accessible by personal access token"}`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/contents/public", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"type":"file","name":"README.md","size":5}]`))
+	})
+
+	walk := func(fsys fs.FS, root string) ([]string, error) {
+		var paths []string
+
+		err := WalkDirConcurrent(fsys, root, 2, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			paths = append(paths, path)
+
+			return nil
+		})
+
+		return paths, err
+	}
+
+	if _, err := walk(New(opt, WithRepository("owner", "repo")), "."); !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("expected walk to fail with fs.ErrPermission, got %v", err)
+	}
+
+	fsys := New(opt, WithRepository("owner", "repo"), WithSkipInaccessible())
+
+	paths, err := walk(fsys, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{".", "private", "public", "public/README.md"}; !slices.Equal(paths, want) {
+		t.Errorf("unexpected paths: got %v, want %v", paths, want)
+	}
+
+	// The cause is kept for callers reading the directory directly
+	if _, err := fs.ReadDir(fsys, "private"); !errors.Is(err, fs.SkipDir) || !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("expected fs.SkipDir and fs.ErrPermission, got %v", err)
+	}
+
+	if _, err := walk(fsys, "private"); !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("expected walk of an inaccessible root to fail with fs.ErrPermission, got %v", err)
+	}
+
+	paths = nil
+
+	err = fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.SkipDir) {
+			return fs.SkipDir
+		}
+
+		paths = append(paths, path)
+
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{".", "private", "public", "public/README.md"}; !slices.Equal(paths, want) {
+		t.Errorf("unexpected paths: got %v, want %v", paths, want)
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	mux, opt := setup(t)
+
+	mux.HandleFunc("GET /repos/owner/repo/contents/README.md", func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.Header.Get("Accept"), mediaTypeRaw; got != want {
+			t.Errorf("unexpected media type: got %q, want %q", got, want)
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte("hello"))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/contents/docs", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Write([]byte(`[{"type":"file","name":"README.md","path":"docs/README.md","size":5}]`))
+	})
+
+	fsys := New(opt, WithRepository("owner", "repo"))
+
+	content, err := fsys.ReadFile("README.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(content) != "hello" {
+		t.Errorf("unexpected content: %q", content)
+	}
+
+	for _, name := range []string{".", "docs"} {
+		if _, err := fsys.ReadFile(name); err == nil {
+			t.Errorf("expected reading directory %q to fail", name)
+		}
+	}
+}
+
+func TestStat(t *testing.T) {
+	mux, opt := setup(t)
+
+	mux.HandleFunc("GET /repos/owner/repo", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"name":"repo"}`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/contents/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"type":"file","name":"README.md","size":5},{"type":"dir","name":"docs"}]`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/contents/docs", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"type":"file","name":"index.md","size":42}]`))
+	})
+
+	fsys := New(opt, WithRepository("owner", "repo"))
+
+	testCases := []struct {
+		name  string
+		isDir bool
+		size  int64
+	}{
+		{".", true, 0},
+		{"README.md", false, 5},
+		{"docs", true, 0},
+		{"docs/index.md", false, 42},
+	}
+
+	for _, tc := range testCases {
+		info, err := fsys.Stat(tc.name)
+		if err != nil {
+			t.Errorf("failed to stat %s: %v", tc.name, err)
+
+			continue
+		}
+
+		if info.Name() != path.Base(tc.name) && tc.name != "." {
+			t.Errorf("unexpected name for %s: %s", tc.name, info.Name())
+		}
+
+		if info.IsDir() != tc.isDir || info.Size() != tc.size {
+			t.Errorf("unexpected info for %s: dir=%v size=%d", tc.name, info.IsDir(), info.Size())
+		}
+	}
+
+	if _, err := fsys.Stat("docs/missing.md"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected fs.ErrNotExist, got %v", err)
+	}
+}
+
+func TestSubWithOptions(t *testing.T) {
+	mux, opt := setup(t)
+
+	mux.HandleFunc("GET /repos/owner/repo/contents/docs/README.md", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ref=" + r.URL.Query().Get("ref")))
+	})
+
+	fsys := New(opt, WithRepository("owner", "repo"))
+
+	sub, err := fsys.SubWithOptions("docs", WithRef("v1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := fs.ReadFile(sub, "README.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(content) != "ref=v1" {
+		t.Errorf("expected sub filesystem to use its own ref, got %q", content)
+	}
+
+	content, err = fs.ReadFile(fsys, "docs/README.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(content) != "ref=" {
+		t.Errorf("expected parent filesystem to be unaffected, got %q", content)
+	}
+}
+
+// fileHandler serves a file the way the Contents API does (including the raw media type).
+func fileHandler(content string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") == mediaTypeRaw {
+			w.Write([]byte(content))
+
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]any{
+			"type":     "file",
+			"name":     path.Base(r.URL.Path),
+			"path":     strings.TrimPrefix(r.URL.Path, "/"),
+			"encoding": "base64",
+			"content":  base64.StdEncoding.EncodeToString([]byte(content)),
+			"size":     len(content),
+		})
+	}
+}
+
+func TestFileSeek(t *testing.T) {
+	mux, opt := setup(t)
+
+	var rawRequests int
+
+	mux.HandleFunc("GET /repos/owner/repo/contents/small.txt", fileHandler("hello world"))
+	mux.HandleFunc("GET /repos/owner/repo/contents/large.txt", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") == mediaTypeRaw {
+			rawRequests++
+
+			w.Write([]byte("hello world"))
+
+			return
+		}
+
+		w.Write([]byte(`{"type":"file","name":"large.txt","encoding":"none","content":"","size":11}`))
+	})
+
+	fsys := New(opt, WithRepository("owner", "repo"))
+
+	for _, name := range []string{"small.txt", "large.txt"} {
+		t.Run(name, func(t *testing.T) {
+			file, err := fsys.Open(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+
+			seeker, ok := file.(io.ReadSeeker)
+			if !ok {
+				t.Fatal("expected file to implement io.Seeker")
+			}
+
+			if _, err := io.ReadAll(seeker); err != nil {
+				t.Fatal(err)
+			}
+
+			if pos, err := seeker.Seek(-5, io.SeekEnd); err != nil || pos != 6 {
+				t.Fatalf("unexpected seek result: %d, %v", pos, err)
+			}
+
+			content, err := io.ReadAll(seeker)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(content) != "world" {
+				t.Errorf("unexpected content after seeking: %q", content)
+			}
+
+			if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+				t.Fatal(err)
+			}
+
+			content, err = io.ReadAll(seeker)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(content) != "hello world" {
+				t.Errorf("unexpected content after rewinding: %q", content)
+			}
+		})
+	}
+
+	if rawRequests != 3 {
+		t.Errorf("expected streamed content to be fetched again when seeking backwards, got %d requests", rawRequests)
+	}
+}
+
+func TestFileSeekEnd(t *testing.T) {
+	mux, opt := setup(t)
+
+	content := strings.Repeat("0123456789", 1000)
+
+	mux.HandleFunc("GET /repos/owner/repo/contents/large.txt", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != mediaTypeRaw {
+			fmt.Fprintf(w, `{"type":"file","name":"large.txt","encoding":"none","content":"","size":%d}`, len(content))
+
+			return
+		}
+
+		http.ServeContent(w, r, "large.txt", time.Time{}, strings.NewReader(content))
+	})
+
+	fsys := New(opt, WithRepository("owner", "repo"))
+
+	f, err := fsys.Open("large.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	seeker := f.(io.ReadSeeker)
+
+	// http.ServeContent determines the size of content this way
+	if pos, err := seeker.Seek(0, io.SeekEnd); err != nil || pos != int64(len(content)) {
+		t.Fatalf("unexpected seek result: %d, %v", pos, err)
+	}
+
+	if n, err := seeker.Read(make([]byte, 1)); n != 0 || err != io.EOF {
+		t.Errorf("expected EOF at the end, got %d, %v", n, err)
+	}
+
+	if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := io.ReadAll(seeker)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(got) != content {
+		t.Error("unexpected content after rewinding")
+	}
+
+	if downloaded := fsys.Stats().BytesDownloaded; downloaded >= int64(2*len(content)) {
+		t.Errorf("expected content to be downloaded once, got %d bytes", downloaded)
+	}
+}
+
+func TestFileReadAt(t *testing.T) {
+	mux, opt := setup(t)
+
+	var ranges []string
+
+	mux.HandleFunc("GET /repos/owner/repo/contents/small.txt", fileHandler("hello world"))
+	mux.HandleFunc("GET /repos/owner/repo/contents/large.txt", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") == mediaTypeRaw {
+			ranges = append(ranges, r.Header.Get("Range"))
+
+			http.ServeContent(w, r, "large.txt", time.Time{}, strings.NewReader("hello world"))
+
+			return
+		}
+
+		w.Write([]byte(`{"type":"file","name":"large.txt","encoding":"none","content":"","size":11}`))
+	})
+
+	fsys := New(opt, WithRepository("owner", "repo"))
+
+	for _, name := range []string{"small.txt", "large.txt"} {
+		t.Run(name, func(t *testing.T) {
+			file, err := fsys.Open(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+
+			readerAt, ok := file.(io.ReaderAt)
+			if !ok {
+				t.Fatal("expected file to implement io.ReaderAt")
+			}
+
+			p := make([]byte, 5)
+
+			if n, err := readerAt.ReadAt(p, 6); err != nil || string(p[:n]) != "world" {
+				t.Errorf("unexpected read result: %q, %v", p[:n], err)
+			}
+
+			if n, err := readerAt.ReadAt(p, 8); err != io.EOF || string(p[:n]) != "rld" {
+				t.Errorf("unexpected read result at the end: %q, %v", p[:n], err)
+			}
+
+			// The read position is not affected
+			content, err := io.ReadAll(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(content) != "hello world" {
+				t.Errorf("unexpected content: %q", content)
+			}
+		})
+	}
+
+	if want := []string{"", "bytes=6-10", "bytes=8-10"}; !slices.Equal(ranges, want) {
+		t.Errorf("unexpected range requests: got %q, want %q", ranges, want)
+	}
+}
+
+func TestFileWriteTo(t *testing.T) {
+	mux, opt := setup(t)
+
+	mux.HandleFunc("GET /repos/owner/repo/contents/small.txt", fileHandler("hello world"))
+	mux.HandleFunc("GET /repos/owner/repo/contents/large.txt", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") == mediaTypeRaw {
+			w.Write([]byte("hello world"))
+
+			return
+		}
+
+		w.Write([]byte(`{"type":"file","name":"large.txt","encoding":"none","content":"","size":11}`))
+	})
+
+	fsys := New(opt, WithRepository("owner", "repo"))
+
+	for _, name := range []string{"small.txt", "large.txt"} {
+		t.Run(name, func(t *testing.T) {
+			file, err := fsys.Open(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+
+			if _, ok := file.(io.WriterTo); !ok {
+				t.Fatal("expected file to implement io.WriterTo")
+			}
+
+			if _, err := file.Read(make([]byte, 6)); err != nil {
+				t.Fatal(err)
+			}
+
+			var buf strings.Builder
+
+			n, err := io.Copy(&buf, file)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if n != 5 || buf.String() != "world" {
+				t.Errorf("unexpected copy result: %d, %q", n, buf.String())
+			}
+
+			// Seeking relies on the read position being tracked
+			if pos, err := file.(io.Seeker).Seek(0, io.SeekCurrent); err != nil || pos != 11 {
+				t.Errorf("unexpected position after copy: %d, %v", pos, err)
+			}
+		})
+	}
+}
+
+func TestHTTPFileServer(t *testing.T) {
+	mux, opt := setup(t)
+
+	var commits int
+
+	mux.HandleFunc("GET /repos/owner/repo/contents/page.html", fileHandler("<h1>hello</h1>"))
+	mux.HandleFunc("GET /repos/owner/repo/commits", func(w http.ResponseWriter, r *http.Request) {
+		commits++
+
+		w.Write([]byte(`[{"sha":"abc","commit":{"committer":{"date":"2024-01-02T03:04:05Z"}}}]`))
+	})
+
+	server := httptest.NewServer(http.FileServer(http.FS(New(opt, WithRepository("owner", "repo")))))
+	t.Cleanup(server.Close)
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/page.html", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req.Header.Set("Range", "bytes=4-8")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusPartialContent {
+		t.Fatalf("unexpected status: %s", resp.Status)
+	}
+
+	if got, want := resp.Header.Get("Last-Modified"), "Tue, 02 Jan 2024 03:04:05 GMT"; got != want {
+		t.Errorf("unexpected Last-Modified header: got %q, want %q", got, want)
+	}
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(content) != "hello" {
+		t.Errorf("unexpected content: %q", content)
+	}
+
+	if commits != 1 {
+		t.Errorf("expected commit time to be fetched once, got %d requests", commits)
+	}
+}
+
+func TestSortedEntries(t *testing.T) {
+	mux, opt := setup(t)
+
+	mux.HandleFunc("GET /users/owner/repos", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"name":"b"},{"name":"c"},{"name":"a"}]`))
+	})
+	mux.HandleFunc("GET /repos/owner/a/contents/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"type":"file","name":"z.txt"},{"type":"dir","name":"docs"},{"type":"file","name":"README.md"}]`))
+	})
+
+	fsys := New(opt, WithOwner("owner"))
+
+	tests := map[string][]string{
+		".": {"a", "b", "c"},
+		"a": {"README.md", "docs", "z.txt"},
+	}
+
+	for name, want := range tests {
+		file, err := fsys.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		entries, err := file.(fs.ReadDirFile).ReadDir(-1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+
+		if !slices.Equal(names, want) {
+			t.Errorf("unexpected entries in %q: got %q, want %q", name, names, want)
+		}
+
+		file.Close()
+	}
+}
+
+func TestLargeDirectory(t *testing.T) {
+	mux, opt := setup(t)
+
+	const total = 1200
+
+	var trees int
+
+	mux.HandleFunc("GET /repos/owner/repo/contents/locales", func(w http.ResponseWriter, r *http.Request) {
+		var entries []map[string]any
+		for i := range contentsLimit {
+			entries = append(entries, map[string]any{"type": "file", "name": fmt.Sprintf("%04d.json", i), "size": 2})
+		}
+
+		json.NewEncoder(w).Encode(entries)
+	})
+	mux.HandleFunc("GET /repos/owner/repo/git/trees/{sha...}", func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.PathValue("sha"), "HEAD:locales"; got != want {
+			t.Errorf("unexpected tree-ish: got %q, want %q", got, want)
+		}
+
+		trees++
+
+		var entries []map[string]any
+		for i := range total {
+			entries = append(entries, map[string]any{"type": "blob", "path": fmt.Sprintf("%04d.json", i), "mode": "100644", "size": 2})
+		}
+
+		json.NewEncoder(w).Encode(map[string]any{"tree": entries})
+	})
+
+	fsys := New(opt, WithRepository("owner", "repo"), WithMetadataCache(time.Minute))
+
+	entries, err := fs.ReadDir(fsys, "locales")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != total {
+		t.Errorf("expected %d entries, got %d", total, len(entries))
+	}
+
+	for _, name := range []string{"locales/1100.json", "locales/1199.json"} {
+		info, err := fs.Stat(fsys, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if info.Size() != 2 {
+			t.Errorf("unexpected size: %d", info.Size())
+		}
+	}
+
+	if trees != 1 {
+		t.Errorf("expected the tree listing to be cached, got %d tree requests", trees)
+	}
+}
+
+func FuzzResolve(f *testing.F) {
+	for _, name := range []string{".", "owner", "owner/repo", "owner/repo/path/to/file", "a/../b", "/owner", "owner//repo", ""} {
+		f.Add("", "", name)
+		f.Add("owner", "", name)
+		f.Add("owner", "repo", name)
+	}
+
+	f.Fuzz(func(t *testing.T, owner string, repo string, name string) {
+		if !fs.ValidPath(owner) || !fs.ValidPath(repo) || strings.Contains(owner, "/") || strings.Contains(repo, "/") || owner == "." || repo == "." {
+			t.Skip()
+		}
+
+		if owner == "" && repo != "" {
+			t.Skip()
+		}
+
+		fsys := New(WithRepository(owner, repo))
+
+		r, err := fsys.resolve("open", name)
+		if !fs.ValidPath(name) {
+			if !errors.Is(err, fs.ErrInvalid) {
+				t.Fatalf("expected invalid path %q to be rejected, got %v", name, err)
+			}
+
+			return
+		}
+
+		if err != nil {
+			if fsys.ref.join(name).owner != "" {
+				t.Fatalf("unexpected error for %q: %v", name, err)
+			}
+
+			return
+		}
+
+		if r.path != "" && !fs.ValidPath(r.path) {
+			t.Errorf("resolved an invalid path for %q: %q", name, r.path)
+		}
+
+		if got, want := r.string(), path.Join("/", owner, repo, name); got != want {
+			t.Errorf("unexpected ref for %q: got %q, want %q", name, got, want)
+		}
+	})
+}
+
+func TestFileInfoSys(t *testing.T) {
+	mux, opt := setup(t)
+
+	mux.HandleFunc("GET /users/owner/repos", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"name":"repo","visibility":"public"}]`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/contents/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"type":"file","name":"README.md","sha":"abc","html_url":"https://github.com/owner/repo/blob/main/README.md"}]`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/contents/README.md", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"type":"file","name":"README.md","sha":"abc","encoding":"base64","content":"","size":0}`))
+	})
+
+	fsys := New(opt, WithOwner("owner"))
+
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := entries[0].Info()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if repo, ok := info.Sys().(*github.Repository); !ok || repo.GetVisibility() != "public" {
+		t.Errorf("unexpected Sys for repository: %#v", info.Sys())
+	}
+
+	entries, err = fs.ReadDir(fsys, "repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err = entries[0].Info()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if content, ok := info.Sys().(*github.RepositoryContent); !ok || content.GetHTMLURL() == "" {
+		t.Errorf("unexpected Sys for directory entry: %#v", info.Sys())
+	}
+
+	info, err = fs.Stat(fsys, "repo/README.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if content, ok := info.Sys().(*github.RepositoryContent); !ok || content.GetSHA() != "abc" {
+		t.Errorf("unexpected Sys for file: %#v", info.Sys())
+	}
+}
+
+func TestFileMetadata(t *testing.T) {
+	mux, opt := setup(t)
+
+	mux.HandleFunc("GET /repos/owner/repo/contents/README.md", func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.URL.Query().Get("ref"), "v1.0.0"; got != want {
+			t.Errorf("unexpected ref: got %q, want %q", got, want)
+		}
+
+		w.Write([]byte(`{
+			"type":"file",
+			"name":"README.md",
+			"sha":"abc",
+			"html_url":"https://github.com/owner/repo/blob/v1.0.0/README.md",
+			"download_url":"https://raw.githubusercontent.com/owner/repo/v1.0.0/README.md",
+			"encoding":"base64",
+			"content":"",
+			"size":0
+		}`))
+	})
+
+	file, err := New(opt, WithRepository("owner", "repo"), WithRef("v1.0.0")).Open("README.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	metadata, ok := file.(FileMetadata)
+	if !ok {
+		t.Fatal("expected file to implement FileMetadata")
+	}
+
+	got := []string{metadata.SHA(), metadata.HTMLURL(), metadata.DownloadURL(), metadata.Ref()}
+	want := []string{
+		"abc",
+		"https://github.com/owner/repo/blob/v1.0.0/README.md",
+		"https://raw.githubusercontent.com/owner/repo/v1.0.0/README.md",
+		"v1.0.0",
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("unexpected metadata: got %q, want %q", got, want)
+	}
+}
+
+func TestAccessors(t *testing.T) {
+	client := github.NewClient(nil)
+
+	fsys := New(WithRepository("owner", "repo"), WithRef("main"), WithClient(client))
+
+	if got := []string{fsys.Owner(), fsys.Repo(), fsys.Ref()}; !slices.Equal(got, []string{"owner", "repo", "main"}) {
+		t.Errorf("unexpected accessor values: %q", got)
+	}
+
+	if fsys.Client() == nil || fsys.Client().BaseURL.String() != client.BaseURL.String() {
+		t.Error("expected client to be based on the configured client")
+	}
+
+	sub, err := New(WithOwner("owner")).Sub("repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := sub.(*FS).Repo(); got != "repo" {
+		t.Errorf("unexpected repository of sub filesystem: %q", got)
+	}
+}
+
+func TestContextVariants(t *testing.T) {
+	mux, opt := setup(t)
+
+	mux.HandleFunc("GET /repos/owner/repo/contents/README.md", fileHandler("hello"))
+	mux.HandleFunc("GET /repos/owner/repo/contents/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"type":"file","name":"README.md","size":5}]`))
+	})
+
+	fsys := New(opt, WithRepository("owner", "repo"))
+
+	calls := map[string]func(ctx context.Context) error{
+		"OpenContext": func(ctx context.Context) error {
+			_, err := fsys.OpenContext(ctx, "README.md")
+			return err
+		},
+		"ReadDirContext": func(ctx context.Context) error {
+			_, err := fsys.ReadDirContext(ctx, ".")
+			return err
+		},
+		"ReadFileContext": func(ctx context.Context) error {
+			_, err := fsys.ReadFileContext(ctx, "README.md")
+			return err
+		},
+		"StatContext": func(ctx context.Context) error {
+			_, err := fsys.StatContext(ctx, "README.md")
+			return err
+		},
+		"GlobContext": func(ctx context.Context) error {
+			matches, err := fsys.GlobContext(ctx, "*.md")
+
+			// Directory listing errors are ignored while globbing: a canceled context results in no matches
+			if err == nil && len(matches) == 0 {
+				return context.Canceled
+			}
+
+			return err
+		},
+	}
+
+	canceled, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	for name, call := range calls {
+		if err := call(t.Context()); err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+		}
+
+		if err := call(canceled); !errors.Is(err, context.Canceled) {
+			t.Errorf("%s: expected canceled context to be used, got %v", name, err)
+		}
+	}
+}
+
+func TestLazyRepositoryListing(t *testing.T) {
+	mux, opt := setup(t)
+
+	var pages []string
+
+	mux.HandleFunc("GET /users/owner/repos", func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		pages = append(pages, page)
+
+		if got, want := r.URL.Query().Get("sort"), "full_name"; got != want {
+			t.Errorf("unexpected sort: got %q, want %q", got, want)
+		}
+
+		switch page {
+		case "", "1":
+			w.Header().Set("Link", `<https://api.github.com/users/owner/repos?page=2>; rel="next"`)
+			w.Write([]byte(`[{"name":"a"},{"name":"b"}]`))
+		case "2":
+			w.Header().Set("Link", `<https://api.github.com/users/owner/repos?page=3>; rel="next"`)
+			w.Write([]byte(`[{"name":"d"},{"name":"c"}]`))
+		case "3":
+			w.Write([]byte(`[{"name":"e"}]`))
+		}
+	})
+
+	file, err := New(opt, WithOwner("owner")).Open(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	dir := file.(fs.ReadDirFile)
+
+	entries, err := dir.ReadDir(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 2 || len(pages) != 1 {
+		t.Errorf("expected only the first page to be fetched, got %d entries from %d pages", len(entries), len(pages))
+	}
+
+	entries, err = dir.ReadDir(-1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+
+	if want := []string{"c", "d", "e"}; !slices.Equal(names, want) {
+		t.Errorf("unexpected remaining entries: got %v, want %v", names, want)
+	}
+
+	if len(pages) != 3 {
+		t.Errorf("expected all pages to be fetched, got %d", len(pages))
+	}
+}
+
+func TestDirRewind(t *testing.T) {
+	mux, opt := setup(t)
+
+	var listings int
+
+	mux.HandleFunc("GET /repos/owner/repo/contents/", func(w http.ResponseWriter, r *http.Request) {
+		listings++
+
+		w.Write([]byte(`[{"type":"file","name":"a.txt"},{"type":"file","name":"b.txt"}]`))
+	})
+
+	file, err := New(opt, WithRepository("owner", "repo")).Open(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	for range 2 {
+		entries, err := file.(fs.ReadDirFile).ReadDir(-1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(entries) != 2 {
+			t.Errorf("expected 2 entries, got %d", len(entries))
+		}
+
+		if _, err := file.(io.Seeker).Seek(0, io.SeekStart); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if listings != 1 {
+		t.Errorf("expected rewinding not to list the directory again, got %d requests", listings)
+	}
+
+	if _, err := file.(io.Seeker).Seek(1, io.SeekStart); !errors.Is(err, fs.ErrInvalid) {
+		t.Errorf("expected seeking to an offset to fail, got %v", err)
+	}
+}
+
+func TestConcurrentRequests(t *testing.T) {
+	mux, opt := setup(t)
+
+	var requests atomic.Int32
+
+	release := make(chan struct{})
+
+	mux.HandleFunc("GET /repos/owner/repo/contents/README.md", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		<-release
+
+		fileHandler("content")(w, r)
+	})
+
+	fsys := New(opt, WithRepository("owner", "repo"))
+
+	var wg sync.WaitGroup
+
+	for range 5 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			content, err := fs.ReadFile(fsys, "README.md")
+			if err != nil {
+				t.Error(err)
+
+				return
+			}
+
+			if string(content) != "content" {
+				t.Errorf("unexpected content: %q", content)
+			}
+		}()
+	}
+
+	// Give every goroutine time to join the in-flight request
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+
+	wg.Wait()
+
+	if got := requests.Load(); got != 1 {
+		t.Errorf("expected concurrent reads to share a single request, got %d requests", got)
+	}
+}
+
+func TestFileStreamsContent(t *testing.T) {
+	mux, opt := setup(t)
+
+	content := strings.Repeat("0123456789", 20)
+
+	// The Contents API wraps base64 content at 60 characters
+	encoded := base64.StdEncoding.EncodeToString([]byte(content))
+
+	var wrapped strings.Builder
+	for chunk := range slices.Chunk([]byte(encoded), 60) {
+		wrapped.Write(chunk)
+		wrapped.WriteString("\n")
+	}
+
+	mux.HandleFunc("GET /repos/owner/repo/contents/data.txt", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"type":     "file",
+			"name":     "data.txt",
+			"encoding": "base64",
+			"content":  wrapped.String(),
+			"size":     len(content),
+		})
+	})
+
+	f, err := New(opt, WithRepository("owner", "repo")).Open("data.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if _, ok := f.(*file).content.(nopSeekCloser); ok {
+		t.Error("expected content to be decoded incrementally")
+	}
+
+	if err := iotest.TestReader(f, []byte(content)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFileReadCancel(t *testing.T) {
+	mux, opt := setup(t)
+
+	mux.HandleFunc("GET /repos/owner/repo/contents/small.txt", fileHandler("hello world"))
+	mux.HandleFunc("GET /repos/owner/repo/contents/large.txt", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != mediaTypeRaw {
+			w.Write([]byte(`{"type":"file","name":"large.txt","encoding":"none","content":"","size":11}`))
+
+			return
+		}
+
+		w.Write([]byte("hello "))
+		w.(http.Flusher).Flush()
+
+		// Hang until the download is aborted
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	})
+
+	for _, name := range []string{"small.txt", "large.txt"} {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(t.Context())
+			defer cancel()
+
+			f, err := New(opt, WithRepository("owner", "repo"), WithContext(ctx)).Open(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			buf := make([]byte, 6)
+			if _, err := io.ReadFull(f, buf); err != nil {
+				t.Fatal(err)
+			}
+
+			cancel()
+
+			if _, err := io.ReadAll(f); !errors.Is(err, context.Canceled) {
+				t.Errorf("expected context.Canceled, got %v", err)
+			}
+		})
+	}
+}
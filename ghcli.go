@@ -0,0 +1,115 @@
+package githubfs
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// ghCLIToken returns the token the gh CLI uses for a host.
+//
+// The token is requested from the gh CLI itself (which also reads the system keyring) if it is installed,
+// otherwise it is read from the hosts.yml file of the gh CLI configuration.
+func ghCLIToken(ctx context.Context, host string) (string, error) {
+	if gh, err := exec.LookPath("gh"); err == nil {
+		out, err := exec.CommandContext(ctx, gh, "auth", "token", "--hostname", host).Output()
+		if token := strings.TrimSpace(string(out)); err == nil && token != "" {
+			return token, nil
+		}
+	}
+
+	dir, err := ghConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	file, err := os.Open(filepath.Join(dir, "hosts.yml"))
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	token, err := parseGHHosts(bufio.NewScanner(file), host)
+	if err != nil {
+		return "", err
+	}
+
+	if token == "" {
+		return "", fmt.Errorf("not logged in to %s (run gh auth login)", host)
+	}
+
+	return token, nil
+}
+
+// ghConfigDir returns the configuration directory of the gh CLI.
+func ghConfigDir() (string, error) {
+	if dir := os.Getenv("GH_CONFIG_DIR"); dir != "" {
+		return dir, nil
+	}
+
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "gh"), nil
+	}
+
+	if dir := os.Getenv("AppData"); runtime.GOOS == "windows" && dir != "" {
+		return filepath.Join(dir, "GitHub CLI"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", errors.New("cannot find gh CLI configuration")
+	}
+
+	return filepath.Join(home, ".config", "gh"), nil
+}
+
+// parseGHHosts returns the token of a host from the hosts.yml file of the gh CLI configuration.
+//
+// Only the subset of YAML written by the gh CLI is supported:
+//
+//	github.com:
+//	    oauth_token: gho_...
+//	    user: octocat
+func parseGHHosts(scanner *bufio.Scanner, host string) (string, error) {
+	var current string
+
+	// indent is the indentation of the keys of the current host.
+	var indent int
+
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+
+		trimmed := strings.TrimLeft(line, " ")
+		lineIndent := len(line) - len(trimmed)
+
+		if lineIndent == 0 {
+			current = strings.Trim(strings.TrimSuffix(trimmed, ":"), `"'`)
+			indent = 0
+
+			continue
+		}
+
+		if current != host {
+			continue
+		}
+
+		if indent == 0 {
+			indent = lineIndent
+		}
+
+		if value, ok := strings.CutPrefix(trimmed, "oauth_token:"); ok && lineIndent == indent {
+			return strings.Trim(strings.TrimSpace(value), `"'`), nil
+		}
+	}
+
+	return "", scanner.Err()
+}
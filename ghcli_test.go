@@ -0,0 +1,84 @@
+package githubfs
+
+import (
+	"bufio"
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseGHHosts(t *testing.T) {
+	const hosts = `github.com:
+    users:
+        octocat:
+            oauth_token: gho_user
+    oauth_token: gho_github
+    user: octocat
+"github.example.com":
+    oauth_token: "gho_enterprise"
+keyring.example.com:
+    user: octocat
+`
+
+	tests := []struct {
+		host string
+		want string
+	}{
+		{"github.com", "gho_github"},
+		{"github.example.com", "gho_enterprise"},
+		{"keyring.example.com", ""},
+		{"unknown.example.com", ""},
+	}
+
+	for _, test := range tests {
+		token, err := parseGHHosts(bufio.NewScanner(strings.NewReader(hosts)), test.host)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if token != test.want {
+			t.Errorf("unexpected token for %s: got %q, want %q", test.host, token, test.want)
+		}
+	}
+}
+
+func TestWithGHCLIAuth(t *testing.T) {
+	mux, opt := setup(t)
+
+	var tokens []string
+
+	mux.HandleFunc("GET /repos/owner/repo", func(w http.ResponseWriter, r *http.Request) {
+		tokens = append(tokens, r.Header.Get("Authorization"))
+
+		w.Write([]byte(`{"name":"repo"}`))
+	})
+
+	dir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(dir, "hosts.yml"), []byte("github.com:\n    oauth_token: gho_token\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Read the configuration directory instead of running the gh CLI
+	t.Setenv("PATH", "")
+	t.Setenv("GH_CONFIG_DIR", dir)
+	t.Setenv("GH_HOST", "")
+
+	if _, err := New(opt, WithRepository("owner", "repo"), WithGHCLIAuth()).Stat("."); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tokens) != 1 || tokens[0] != "Bearer gho_token" {
+		t.Errorf("unexpected authorization headers: %q", tokens)
+	}
+
+	t.Setenv("GH_CONFIG_DIR", t.TempDir())
+
+	if _, err := New(opt, WithRepository("owner", "repo"), WithGHCLIAuth()).Stat("."); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected missing credentials to be reported, got %v", err)
+	}
+}
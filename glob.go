@@ -0,0 +1,221 @@
+package githubfs
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/google/go-github/v74/github"
+)
+
+// GlobStrategy selects how [FS.Glob] finds matches (see [WithGlobStrategy]).
+type GlobStrategy int
+
+const (
+	// GlobAuto chooses a strategy based on the shape of the pattern and the size of the repository:
+	//   - a cached tree is matched in memory if available
+	//   - patterns confined to a single directory list that directory
+	//   - other patterns are matched against the recursive tree of the repository
+	//     (fetching subtrees individually if the tree is too large to fetch in a single request)
+	//
+	// Code search is never used automatically, because its results are incomplete (see [GlobSearch]).
+	GlobAuto GlobStrategy = iota
+
+	// GlobTree matches patterns against the recursive tree of the repository fetched using the Git Trees API.
+	GlobTree
+
+	// GlobSearch finds candidates using the code search API.
+	//
+	// Code search only covers the default branch and only supports patterns
+	// matching a literal file name or extension (e.g. "*/*.md").
+	// Files that are not indexed (e.g. large or binary files) are not found.
+	// If the search fails (e.g. due to its separate rate limit), matches are found using the tree instead.
+	GlobSearch
+
+	// GlobWalk lists every directory the pattern can match in.
+	GlobWalk
+)
+
+// Glob implements the [fs.GlobFS] interface.
+//
+// Inside a repository, the whole tree is fetched with a single request using the Git Trees API
+// and matched in memory, instead of listing every directory (see [GlobAuto] for details).
+//
+// Forced strategies (see [WithGlobStrategy]) fall back to walking directories when they cannot serve a pattern.
+func (f *FS) Glob(pattern string) ([]string, error) {
+	return f.glob(f.ctx, pattern)
+}
+
+// GlobContext is like [FS.Glob], but uses ctx instead of the configured context.
+func (f *FS) GlobContext(ctx context.Context, pattern string) ([]string, error) {
+	return f.glob(ctx, pattern)
+}
+
+func (f *FS) glob(ctx context.Context, pattern string) ([]string, error) {
+	// Check pattern is well-formed.
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+
+	if !hasMeta(pattern) {
+		if _, err := f.stat(ctx, pattern); err != nil {
+			return nil, nil
+		}
+
+		return []string{pattern}, nil
+	}
+
+	if f.ref.owner == "" || f.ref.repo == "" {
+		return fs.Glob(noGlobFS{ctx: ctx, fsys: f}, pattern)
+	}
+
+	revision, err := f.resolveRevision(ctx, f.ref.owner, f.ref.repo)
+	if err != nil {
+		return nil, err
+	}
+
+	strategy := f.globStrategy
+
+	if strategy == GlobAuto {
+		switch {
+		case f.cachedTree(f.ref, revision) != nil:
+			strategy = GlobTree
+		case !hasMeta(path.Dir(pattern)):
+			strategy = GlobWalk
+		default:
+			strategy = GlobTree
+		}
+	}
+
+	if strategy == GlobTree {
+		return f.globTree(ctx, pattern, revision)
+	}
+
+	if strategy == GlobSearch {
+		matches, ok, err := f.globSearch(ctx, pattern)
+		if err != nil {
+			return f.globTree(ctx, pattern, revision)
+		}
+
+		if ok {
+			return matches, nil
+		}
+	}
+
+	return fs.Glob(noGlobFS{ctx: ctx, fsys: f}, pattern)
+}
+
+// globTree matches a pattern against the recursive tree of the repository.
+//
+// Truncated trees are completed by fetching subtrees individually.
+func (f *FS) globTree(ctx context.Context, pattern string, revision string) ([]string, error) {
+	tree, err := f.getCompleteTree(ctx, "glob", f.ref, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []string
+
+	for _, entry := range tree.Entries {
+		if ok, _ := path.Match(pattern, entry.GetPath()); ok {
+			matches = append(matches, entry.GetPath())
+		}
+	}
+
+	slices.Sort(matches)
+
+	return matches, nil
+}
+
+// globSearch finds candidates for a pattern using code search.
+//
+// Returns false if code search cannot serve the pattern or the results are incomplete.
+func (f *FS) globSearch(ctx context.Context, pattern string) ([]string, bool, error) {
+	// Code search only indexes the default branch.
+	if f.revision != "" || !f.at.IsZero() {
+		return nil, false, nil
+	}
+
+	var qualifier string
+
+	base := path.Base(pattern)
+
+	if ext, ok := strings.CutPrefix(base, "*."); ok && !hasMeta(ext) {
+		qualifier = "extension:" + ext
+	} else if !hasMeta(base) {
+		qualifier = "filename:" + base
+	} else {
+		return nil, false, nil
+	}
+
+	query := fmt.Sprintf("repo:%s/%s %s", f.ref.owner, f.ref.repo, qualifier)
+	if f.ref.path != "" {
+		query += " path:" + f.ref.path
+	}
+
+	opts := &github.SearchOptions{ListOptions: github.ListOptions{PerPage: f.perPage}}
+
+	var matches []string
+
+	for {
+		result, resp, err := f.client.Search.Code(f.ctxFn(ctx), query, opts)
+		if err := handleErr(err, "glob", f.ref.string()); err != nil {
+			return nil, false, err
+		}
+
+		if result.GetIncompleteResults() {
+			return nil, false, nil
+		}
+
+		for _, code := range result.CodeResults {
+			name := code.GetPath()
+
+			if f.ref.path != "" {
+				var ok bool
+
+				name, ok = strings.CutPrefix(name, f.ref.path+"/")
+				if !ok {
+					continue
+				}
+			}
+
+			if ok, _ := path.Match(pattern, name); ok {
+				matches = append(matches, name)
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		opts.Page = resp.NextPage
+	}
+
+	slices.Sort(matches)
+
+	return slices.Compact(matches), true, nil
+}
+
+// noGlobFS hides the Glob method of a filesystem, so that [fs.Glob] can fall back to listing directories.
+//
+// Directories are listed using the context of the Glob call.
+type noGlobFS struct {
+	ctx  context.Context
+	fsys *FS
+}
+
+func (f noGlobFS) Open(name string) (fs.File, error) {
+	return f.fsys.open(f.ctx, name)
+}
+
+func (f noGlobFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return f.fsys.readDir(f.ctx, name)
+}
+
+// hasMeta reports whether path contains any of the magic characters recognized by [path.Match].
+func hasMeta(path string) bool {
+	return strings.ContainsAny(path, `*?[\`)
+}
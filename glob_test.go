@@ -0,0 +1,219 @@
+package githubfs
+
+import (
+	"fmt"
+	"io/fs"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestGlob(t *testing.T) {
+	mux, opt := setup(t)
+
+	var trees int
+
+	mux.HandleFunc("GET /repos/owner/repo/git/trees/{sha...}", func(w http.ResponseWriter, r *http.Request) {
+		trees++
+
+		if r.URL.Query().Get("recursive") != "1" {
+			t.Error("expected a recursive tree request")
+		}
+
+		w.Write([]byte(`{"tree":[
+			{"path":"README.md","type":"blob"},
+			{"path":"docs","type":"tree"},
+			{"path":"docs/index.md","type":"blob"},
+			{"path":"docs/config.yaml","type":"blob"},
+			{"path":"docs/guides","type":"tree"},
+			{"path":"docs/guides/intro.md","type":"blob"}
+		]}`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/contents/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"type":"file","name":"README.md","size":5}]`))
+	})
+
+	fsys := New(opt, WithRepository("owner", "repo"), WithGlobStrategy(GlobTree))
+
+	testCases := []struct {
+		pattern string
+		want    []string
+	}{
+		{"*.md", []string{"README.md"}},
+		{"docs/*.md", []string{"docs/index.md"}},
+		{"*/*/*.md", []string{"docs/guides/intro.md"}},
+		{"docs/*", []string{"docs/config.yaml", "docs/guides", "docs/index.md"}},
+		{"README.md", []string{"README.md"}},
+	}
+
+	for _, tc := range testCases {
+		matches, err := fs.Glob(fsys, tc.pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !slices.Equal(matches, tc.want) {
+			t.Errorf("unexpected matches for %q: got %v, want %v", tc.pattern, matches, tc.want)
+		}
+	}
+
+	if trees != 4 {
+		t.Errorf("expected one tree request per pattern with meta characters, got %d", trees)
+	}
+
+	if _, err := fs.Glob(fsys, "["); err == nil {
+		t.Error("expected malformed pattern to fail")
+	}
+}
+
+func TestGlobStrategy(t *testing.T) {
+	mux, opt := setup(t)
+
+	var trees, searches int
+
+	truncated := true
+	searchStatus := http.StatusOK
+
+	mux.HandleFunc("GET /repos/owner/repo/git/trees/{sha...}", func(w http.ResponseWriter, r *http.Request) {
+		trees++
+
+		fmt.Fprintf(w, `{"truncated":%t,"tree":[{"path":"docs","type":"tree"},{"path":"docs/index.md","type":"blob"}]}`, truncated)
+	})
+	mux.HandleFunc("GET /repos/owner/repo/contents/docs", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"type":"file","name":"index.md","size":5},{"type":"file","name":"config.yaml","size":5}]`))
+	})
+	mux.HandleFunc("GET /search/code", func(w http.ResponseWriter, r *http.Request) {
+		searches++
+
+		if searchStatus != http.StatusOK {
+			w.WriteHeader(searchStatus)
+			w.Write([]byte(`{"message":"Validation Failed"}`))
+
+			return
+		}
+
+		if got, want := r.URL.Query().Get("q"), "repo:owner/repo extension:md"; got != want {
+			t.Errorf("unexpected search query: got %q, want %q", got, want)
+		}
+
+		w.Write([]byte(`{"total_count":2,"items":[{"path":"docs/index.md"},{"path":"README.md"}]}`))
+	})
+
+	t.Run("Walk", func(t *testing.T) {
+		trees, searches = 0, 0
+
+		matches, err := fs.Glob(New(opt, WithRepository("owner", "repo")), "docs/*.md")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !slices.Equal(matches, []string{"docs/index.md"}) {
+			t.Errorf("unexpected matches: %v", matches)
+		}
+
+		if trees != 0 || searches != 0 {
+			t.Errorf("expected a single directory listing, got %d tree and %d search requests", trees, searches)
+		}
+	})
+
+	t.Run("Search", func(t *testing.T) {
+		trees, searches = 0, 0
+
+		matches, err := fs.Glob(New(opt, WithRepository("owner", "repo"), WithGlobStrategy(GlobSearch)), "*/*.md")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !slices.Equal(matches, []string{"docs/index.md"}) {
+			t.Errorf("unexpected matches: %v", matches)
+		}
+
+		if trees != 0 || searches != 1 {
+			t.Errorf("expected matches to be found using code search, got %d tree and %d search requests", trees, searches)
+		}
+	})
+
+	t.Run("CachedTree", func(t *testing.T) {
+		trees, searches = 0, 0
+		truncated = false
+
+		fsys := New(opt, WithRepository("owner", "repo"), WithRef("0123456789abcdef0123456789abcdef01234567"))
+
+		for _, pattern := range []string{"*/*.md", "docs/*.md"} {
+			matches, err := fs.Glob(fsys, pattern)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !slices.Equal(matches, []string{"docs/index.md"}) {
+				t.Errorf("unexpected matches for %q: %v", pattern, matches)
+			}
+		}
+
+		if trees != 1 {
+			t.Errorf("expected the tree at a commit to be fetched once, got %d requests", trees)
+		}
+	})
+
+	t.Run("SearchError", func(t *testing.T) {
+		trees, searches = 0, 0
+		searchStatus = http.StatusUnprocessableEntity
+
+		matches, err := fs.Glob(New(opt, WithRepository("owner", "repo"), WithGlobStrategy(GlobSearch)), "*/*.md")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !slices.Equal(matches, []string{"docs/index.md"}) {
+			t.Errorf("unexpected matches: %v", matches)
+		}
+
+		if trees != 1 || searches != 1 {
+			t.Errorf("expected a failed search to fall back to the tree, got %d tree and %d search requests", trees, searches)
+		}
+	})
+}
+
+func TestGlobTruncatedTree(t *testing.T) {
+	mux, opt := setup(t)
+
+	var truncated int
+
+	mux.HandleFunc("GET /repos/owner/repo/git/trees/{sha...}", func(w http.ResponseWriter, r *http.Request) {
+		recursive := r.URL.Query().Get("recursive") == "1"
+
+		switch sha := r.PathValue("sha"); {
+		case sha == "HEAD" && recursive:
+			truncated++
+
+			w.Write([]byte(`{"sha":"root","truncated":true,"tree":[{"path":"README.md","type":"blob"}]}`))
+		case sha == "root" && !recursive:
+			w.Write([]byte(`{"sha":"root","tree":[{"path":"README.md","type":"blob"},{"path":"docs","type":"tree","sha":"d1"}]}`))
+		case sha == "d1" && recursive:
+			w.Write([]byte(`{"sha":"d1","tree":[{"path":"guides","type":"tree","sha":"g1"},{"path":"guides/intro.md","type":"blob"}]}`))
+		default:
+			t.Errorf("unexpected tree request: %s", r.URL)
+		}
+	})
+
+	mux.HandleFunc("GET /search/code", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("code search should not be used for truncated trees")
+	})
+
+	for _, strategy := range []GlobStrategy{GlobAuto, GlobTree} {
+		fsys := New(opt, WithRepository("owner", "repo"), WithGlobStrategy(strategy))
+
+		matches, err := fs.Glob(fsys, "*/*/*.md")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if want := []string{"docs/guides/intro.md"}; !slices.Equal(matches, want) {
+			t.Errorf("unexpected matches: got %v, want %v", matches, want)
+		}
+	}
+
+	if truncated != 2 {
+		t.Errorf("expected the truncated tree to be fetched once per filesystem, got %d requests", truncated)
+	}
+}
@@ -2,6 +2,10 @@ module github.com/sagikazarmark/go-github-fs
 
 go 1.24.0
 
-require github.com/google/go-github/v74 v74.0.0
+require (
+	github.com/google/go-github/v74 v74.0.0
+	golang.org/x/oauth2 v0.32.0
+	golang.org/x/sync v0.17.0
+)
 
 require github.com/google/go-querystring v1.1.0 // indirect
@@ -0,0 +1,466 @@
+package githubfs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/google/go-github/v74/github"
+)
+
+// graphqlInlineLimit is the maximum size of file contents kept from directory queries (see [WithGraphQLBackend]).
+const graphqlInlineLimit = 64 << 10
+
+// graphqlBatchSize is the maximum number of paths requested in a single query.
+const graphqlBatchSize = 50
+
+const graphqlObjectFragment = `
+fragment blob on Blob { oid byteSize isBinary isTruncated text }
+fragment object on GitObject {
+	__typename
+	oid
+	...blob
+	... on Tree { entries { name type mode object { ...blob } } }
+}`
+
+// graphqlObject is a git object returned by the GraphQL API.
+type graphqlObject struct {
+	Typename    string  `json:"__typename"`
+	OID         string  `json:"oid"`
+	ByteSize    int64   `json:"byteSize"`
+	IsBinary    bool    `json:"isBinary"`
+	IsTruncated bool    `json:"isTruncated"`
+	Text        *string `json:"text"`
+
+	Entries []graphqlTreeEntry `json:"entries"`
+}
+
+type graphqlTreeEntry struct {
+	Name   string         `json:"name"`
+	Type   string         `json:"type"`
+	Mode   int            `json:"mode"`
+	Object *graphqlObject `json:"object"`
+}
+
+// content returns the content of a blob, if the API returned it.
+func (o *graphqlObject) content() ([]byte, bool) {
+	if o == nil || o.Text == nil || o.IsBinary || o.IsTruncated {
+		return nil, false
+	}
+
+	return []byte(*o.Text), true
+}
+
+// queryObjects fetches the objects at paths (relative to the root of the repository r points to) with a single query.
+//
+// Paths that do not exist are missing from the result.
+func (f *FS) queryObjects(ctx context.Context, op string, r ref, revision string, paths []string) (map[string]*graphqlObject, error) {
+	var query strings.Builder
+
+	query.WriteString("query($owner: String!, $repo: String!")
+
+	variables := map[string]any{
+		"owner": r.owner,
+		"repo":  r.repo,
+	}
+
+	for i, p := range paths {
+		fmt.Fprintf(&query, ", $e%d: String!", i)
+		variables[fmt.Sprintf("e%d", i)] = graphqlExpression(revision, p)
+	}
+
+	query.WriteString(") { repository(owner: $owner, name: $repo) {")
+
+	for i := range paths {
+		fmt.Fprintf(&query, " o%d: object(expression: $e%d) { ...object }", i, i)
+	}
+
+	query.WriteString(" } }")
+	query.WriteString(graphqlObjectFragment)
+
+	var result struct {
+		Data struct {
+			Repository map[string]*graphqlObject `json:"repository"`
+		} `json:"data"`
+		Errors []struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+
+	if err := f.queryGraphQL(ctx, query.String(), variables, &result); err != nil {
+		return nil, handleErr(err, op, r.string())
+	}
+
+	for _, e := range result.Errors {
+		if e.Type == "NOT_FOUND" {
+			return nil, &fs.PathError{Op: op, Path: r.string(), Err: fs.ErrNotExist}
+		}
+
+		return nil, &fs.PathError{Op: op, Path: r.string(), Err: errors.New(e.Message)}
+	}
+
+	objects := make(map[string]*graphqlObject, len(paths))
+
+	for i, p := range paths {
+		if object := result.Data.Repository[fmt.Sprintf("o%d", i)]; object != nil {
+			objects[p] = object
+		}
+	}
+
+	return objects, nil
+}
+
+// graphqlExpression returns an expression selecting the object at a path at a git reference.
+//
+// Unlike [treeish], the root of the repository selects the root tree (instead of the commit).
+func graphqlExpression(revision string, p string) string {
+	if revision == "" {
+		revision = "HEAD"
+	}
+
+	if p == "." {
+		p = ""
+	}
+
+	return revision + ":" + p
+}
+
+// queryGraphQL sends a query to the GraphQL API.
+func (f *FS) queryGraphQL(ctx context.Context, query string, variables map[string]any, v any) error {
+	body, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	if err != nil {
+		return err
+	}
+
+	// The GraphQL API is served next to the REST API (at /api/graphql on GitHub Enterprise Server).
+	endpoint := "graphql"
+	if strings.HasSuffix(f.client.BaseURL.Path, "/api/v3/") {
+		endpoint = "../graphql"
+	}
+
+	req, err := f.client.NewRequest(http.MethodPost, endpoint, json.RawMessage(body))
+	if err != nil {
+		return err
+	}
+
+	_, err = f.client.Do(f.ctxFn(ctx), req, v)
+
+	return err
+}
+
+// graphqlEntries returns the entries of a tree returned by the GraphQL API.
+//
+// Contents of small text files are cached (see [WithGraphQLBackend]).
+func (f *FS) graphqlEntries(r ref, revision string, tree *graphqlObject) []*dirEntry {
+	modes := make(map[string]string, len(tree.Entries))
+	dirModes := staticModes(modes)
+
+	entries := make([]*dirEntry, 0, len(tree.Entries))
+
+	for _, e := range tree.Entries {
+		if e.Type == "commit" {
+			continue
+		}
+
+		mode := fmt.Sprintf("%06o", e.Mode)
+		modes[e.Name] = mode
+
+		entry := &github.TreeEntry{
+			Path: github.Ptr(e.Name),
+			Mode: github.Ptr(mode),
+			Type: github.Ptr(e.Type),
+		}
+
+		if e.Object != nil {
+			entry.SHA = github.Ptr(e.Object.OID)
+			entry.Size = github.Ptr(int(e.Object.ByteSize))
+
+			if content, ok := e.Object.content(); ok && e.Object.ByteSize <= graphqlInlineLimit {
+				f.cacheBlob(r.join(e.Name), revision, content)
+			}
+		}
+
+		entries = append(entries, &dirEntry{
+			name:    e.Name,
+			isDir:   e.Type == "tree",
+			size:    int64(entry.GetSize()),
+			modes:   dirModes,
+			modTime: f.modTime(f.ctx, r.join(e.Name), revision),
+			sys:     entry,
+		})
+	}
+
+	sortEntries(entries)
+
+	return entries
+}
+
+// cacheBlob caches the content of a file fetched along with its directory.
+//
+// Content larger than the per-file memory limit is not kept (see [WithMemoryLimit]).
+func (f *FS) cacheBlob(r ref, revision string, content []byte) {
+	if !f.memory.allows(len(content)) {
+		return
+	}
+
+	f.revisions.mu.Lock()
+	defer f.revisions.mu.Unlock()
+
+	f.revisions.blobs[r.string()+"@"+revision] = content
+}
+
+// cachedBlob returns the content of a file fetched along with its directory, if any.
+func (f *FS) cachedBlob(r ref, revision string) ([]byte, bool) {
+	f.revisions.mu.Lock()
+	defer f.revisions.mu.Unlock()
+
+	content, ok := f.revisions.blobs[r.string()+"@"+revision]
+
+	return content, ok
+}
+
+// openGraphQL serves a directory or a small text file using the GraphQL API.
+//
+// Returns false if the content of a file is not available through the GraphQL API (e.g. because it is binary).
+func (f *FS) openGraphQL(ctx context.Context, r ref, revision string) (fs.File, bool, error) {
+	if content, ok := f.cachedBlob(r, revision); ok {
+		file, err := f.normalizeFile(f.graphqlFile(ctx, r, revision, content))
+
+		return file, err == nil, err
+	}
+
+	objects, err := f.queryObjects(ctx, "open", ref{owner: r.owner, repo: r.repo}, revision, []string{r.path})
+	if err != nil {
+		return nil, false, err
+	}
+
+	object, ok := objects[r.path]
+	if !ok {
+		return nil, false, &fs.PathError{Op: "open", Path: r.string(), Err: fs.ErrNotExist}
+	}
+
+	if object.Typename == "Tree" {
+		return &dir{
+			name:    path.Base(r.string()),
+			entries: f.graphqlEntries(r, revision, object),
+			modTime: f.modTime(ctx, r, revision),
+		}, true, nil
+	}
+
+	content, ok := object.content()
+	if !ok {
+		return nil, false, nil
+	}
+
+	file, err := f.normalizeFile(f.graphqlFile(ctx, r, revision, content))
+
+	return file, err == nil, err
+}
+
+// graphqlFile returns a file serving content fetched using the GraphQL API.
+func (f *FS) graphqlFile(ctx context.Context, r ref, revision string, content []byte) *file {
+	return &file{
+		name:     path.Base(r.path),
+		size:     int64(len(content)),
+		modes:    f.treeModes(ctx, r.parent(), revision),
+		modTime:  f.modTime(ctx, r, revision),
+		content:  nopSeekCloser{bytes.NewReader(content)},
+		revision: revision,
+	}
+}
+
+// walkGraphQL prefetches the tree at root (relative to the filesystem root) using batched GraphQL queries,
+// one query per level of the tree (for up to [graphqlBatchSize] directories).
+//
+// Returns false if root is not a directory inside a repository, so the caller can fall back to listing directories.
+// Any other error (e.g. authentication, rate limits or a path of a batch not being found) is returned.
+func (f *FS) walkGraphQL(ctx context.Context, root string) (treeIndex, bool, error) {
+	r, err := f.resolve("walk", root)
+	if err != nil || r.repo == "" {
+		return nil, false, nil
+	}
+
+	revision, err := f.resolveRevision(ctx, r.owner, r.repo)
+	if err != nil {
+		return nil, false, err
+	}
+
+	repo := ref{owner: r.owner, repo: r.repo}
+	idx := make(treeIndex)
+	level := []string{"."}
+
+	for len(level) > 0 {
+		var next []string
+
+		for batch := range slices.Chunk(level, graphqlBatchSize) {
+			paths := make([]string, len(batch))
+			for i, rel := range batch {
+				paths[i] = path.Join(r.path, rel)
+			}
+
+			objects, err := f.queryObjects(ctx, "walk", repo, revision, paths)
+			if err != nil {
+				return nil, false, err
+			}
+
+			for i, rel := range batch {
+				object, ok := objects[paths[i]]
+				if !ok || object.Typename != "Tree" {
+					if rel == "." {
+						return nil, false, nil
+					}
+
+					continue
+				}
+
+				idx[rel] = f.graphqlEntries(repo.join(paths[i]), revision, object)
+
+				for _, entry := range idx[rel] {
+					if entry.isDir {
+						next = append(next, path.Join(rel, entry.name))
+					}
+				}
+			}
+		}
+
+		level = next
+	}
+
+	return idx, true, nil
+}
+
+const graphqlRepositoriesQuery = `
+query($owner: String!, $first: Int!, $after: String) {
+	repositoryOwner(login: $owner) {
+		repositories(first: $first, after: $after, ownerAffiliations: OWNER, privacy: PUBLIC, orderBy: {field: NAME, direction: ASC}) {
+			nodes { name pushedAt updatedAt isArchived isPrivate visibility }
+			pageInfo { hasNextPage endCursor }
+		}
+	}
+}`
+
+// graphqlRepository is a repository returned by the GraphQL API.
+type graphqlRepository struct {
+	Name       string           `json:"name"`
+	PushedAt   github.Timestamp `json:"pushedAt"`
+	UpdatedAt  github.Timestamp `json:"updatedAt"`
+	IsArchived bool             `json:"isArchived"`
+	IsPrivate  bool             `json:"isPrivate"`
+	Visibility string           `json:"visibility"`
+}
+
+// listRepositoriesGraphQL lists repositories for a given owner using the GraphQL API, starting at a cursor.
+//
+// Entries carry the same [github.Repository] values as REST listings (with the fields the query returns).
+// Like the REST endpoint listing the repositories of a user, only public repositories are listed.
+func (f *FS) listRepositoriesGraphQL(ctx context.Context, owner string, start listCursor) (fs.File, error) {
+	d := &dir{
+		name: owner,
+	}
+
+	var pages listPages
+
+	after := start.after
+	page := max(start.page, 1)
+
+	d.more = func() ([]*dirEntry, bool, error) {
+		pages = append(pages, listCursor{page: page, after: after, index: len(d.entries)})
+
+		variables := map[string]any{
+			"owner": owner,
+			"first": f.perPage,
+		}
+
+		if after != "" {
+			variables["after"] = after
+		}
+
+		var result struct {
+			Data struct {
+				RepositoryOwner *struct {
+					Repositories struct {
+						Nodes    []graphqlRepository `json:"nodes"`
+						PageInfo struct {
+							HasNextPage bool   `json:"hasNextPage"`
+							EndCursor   string `json:"endCursor"`
+						} `json:"pageInfo"`
+					} `json:"repositories"`
+				} `json:"repositoryOwner"`
+			} `json:"data"`
+			Errors []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		}
+
+		if err := f.queryGraphQL(ctx, graphqlRepositoriesQuery, variables, &result); err != nil {
+			return nil, false, handleErr(err, "open", "/"+owner)
+		}
+
+		if len(result.Errors) > 0 {
+			return nil, false, &fs.PathError{Op: "open", Path: "/" + owner, Err: errors.New(result.Errors[0].Message)}
+		}
+
+		if result.Data.RepositoryOwner == nil {
+			return nil, false, &fs.PathError{Op: "open", Path: "/" + owner, Err: fs.ErrNotExist}
+		}
+
+		repos := result.Data.RepositoryOwner.Repositories
+
+		entries := make([]*dirEntry, len(repos.Nodes))
+		for i, repo := range repos.Nodes {
+			pushedAt := repo.PushedAt
+			if pushedAt.IsZero() {
+				pushedAt = repo.UpdatedAt
+			}
+
+			entries[i] = &dirEntry{
+				name:    repo.Name,
+				isDir:   true,
+				size:    0,
+				modTime: fixedModTime(pushedAt.Time),
+				sys: &github.Repository{
+					Name:       github.Ptr(repo.Name),
+					PushedAt:   &repo.PushedAt,
+					UpdatedAt:  &repo.UpdatedAt,
+					Archived:   github.Ptr(repo.IsArchived),
+					Private:    github.Ptr(repo.IsPrivate),
+					Visibility: github.Ptr(strings.ToLower(repo.Visibility)),
+				},
+			}
+		}
+
+		page++
+		after = repos.PageInfo.EndCursor
+
+		return entries, repos.PageInfo.HasNextPage, nil
+	}
+
+	d.cursor = func() string {
+		if d.offset < len(d.entries) {
+			return pages.cursor(d.offset).encode()
+		}
+
+		if d.more != nil {
+			return listCursor{page: page, after: after}.encode()
+		}
+
+		return ""
+	}
+
+	// Fetch the first page to report errors when opening the directory
+	if err := d.fill(1); err != nil {
+		return nil, err
+	}
+
+	d.offset = min(start.index, len(d.entries))
+
+	return d, nil
+}
@@ -0,0 +1,260 @@
+package githubfs
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v74/github"
+)
+
+func TestWithGraphQLBackend(t *testing.T) {
+	mux, opt := setup(t)
+
+	objects := map[string]string{
+		"main:":              `{"__typename":"Tree","oid":"t1","entries":[{"name":"README.md","type":"blob","mode":33188,"object":{"oid":"b1","byteSize":6,"text":"# repo"}},{"name":"docs","type":"tree","mode":16384,"object":{}}]}`,
+		"main:docs":          `{"__typename":"Tree","oid":"t2","entries":[{"name":"run.sh","type":"blob","mode":33261,"object":{"oid":"b2","byteSize":9,"text":"#!/bin/sh"}},{"name":"logo.png","type":"blob","mode":33188,"object":{"oid":"b3","byteSize":4,"isBinary":true}}]}`,
+		"main:docs/logo.png": `{"__typename":"Blob","oid":"b3","byteSize":4,"isBinary":true}`,
+	}
+
+	var queries [][]string
+
+	mux.HandleFunc("POST /graphql", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Query     string            `json:"query"`
+			Variables map[string]string `json:"variables"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+
+		var expressions []string
+
+		repository := make(map[string]json.RawMessage)
+
+		for key, value := range body.Variables {
+			alias, ok := strings.CutPrefix(key, "e")
+			if !ok {
+				continue
+			}
+
+			expressions = append(expressions, value)
+
+			if object, ok := objects[value]; ok {
+				repository["o"+alias] = json.RawMessage(object)
+			}
+		}
+
+		slices.Sort(expressions)
+		queries = append(queries, expressions)
+
+		json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"repository": repository}})
+	})
+	mux.HandleFunc("GET /repos/owner/repo/contents/docs/logo.png", fileHandler("\x89PNG"))
+
+	fsys := New(opt, WithRepository("owner", "repo"), WithRef("main"), WithGraphQLBackend())
+
+	var paths []string
+
+	for entry, err := range Walk(fsys, ".") {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		paths = append(paths, entry.Path)
+	}
+
+	if want := []string{".", "README.md", "docs", "docs/logo.png", "docs/run.sh"}; !slices.Equal(paths, want) {
+		t.Errorf("unexpected walk: got %v, want %v", paths, want)
+	}
+
+	if want := [][]string{{"main:"}, {"main:docs"}}; !slices.EqualFunc(queries, want, slices.Equal) {
+		t.Errorf("expected one query per level, got %v", queries)
+	}
+
+	entries, err := fs.ReadDir(fsys, "docs")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := entries[1].Info()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Name() != "run.sh" || info.Mode() != 0o755 || info.Size() != 9 {
+		t.Errorf("unexpected file info: %s %v %d", info.Name(), info.Mode(), info.Size())
+	}
+
+	queries = nil
+
+	content, err := fs.ReadFile(fsys, "docs/run.sh")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(content) != "#!/bin/sh" {
+		t.Errorf("unexpected content: %q", content)
+	}
+
+	if len(queries) != 0 {
+		t.Errorf("expected content fetched along with the directory to be served from memory, got %v", queries)
+	}
+
+	file, err := fsys.Open("docs/logo.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	content, err = io.ReadAll(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(content) != "\x89PNG" {
+		t.Errorf("expected binary content to be fetched using the REST API, got %q", content)
+	}
+}
+
+func TestWalkGraphQLError(t *testing.T) {
+	mux, opt := setup(t)
+
+	var listed bool
+
+	mux.HandleFunc("POST /graphql", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"errors":[{"type":"RATE_LIMITED","message":"API rate limit exceeded"}]}`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/contents/", func(w http.ResponseWriter, r *http.Request) {
+		listed = true
+
+		w.Write([]byte(`[]`))
+	})
+
+	fsys := New(opt, WithRepository("owner", "repo"), WithRef("main"), WithGraphQLBackend())
+
+	var errs []error
+
+	for _, err := range Walk(fsys, ".") {
+		errs = append(errs, err)
+	}
+
+	if len(errs) != 1 || errs[0] == nil || !strings.Contains(errs[0].Error(), "rate limit") {
+		t.Errorf("expected the query error to be yielded, got %v", errs)
+	}
+
+	if listed {
+		t.Error("expected the walk not to fall back to listing directories")
+	}
+}
+
+func TestWithGraphQLBackendRepositories(t *testing.T) {
+	mux, opt := setup(t)
+
+	var cursors []string
+
+	mux.HandleFunc("POST /graphql", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Query     string `json:"query"`
+			Variables struct {
+				Owner string `json:"owner"`
+				After string `json:"after"`
+			} `json:"variables"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+
+		// The REST listing only returns public repositories
+		if !strings.Contains(body.Query, "privacy: PUBLIC") {
+			t.Error("expected the query to list public repositories only")
+		}
+
+		if body.Variables.Owner != "owner" {
+			w.Write([]byte(`{"data":{"repositoryOwner":null}}`))
+
+			return
+		}
+
+		cursors = append(cursors, body.Variables.After)
+
+		switch body.Variables.After {
+		case "":
+			w.Write([]byte(`{"data":{"repositoryOwner":{"repositories":{
+				"nodes":[{"name":"a","pushedAt":"2024-01-02T03:04:05Z","isArchived":true,"visibility":"PUBLIC"},{"name":"b","updatedAt":"2024-02-02T00:00:00Z","visibility":"PUBLIC"}],
+				"pageInfo":{"hasNextPage":true,"endCursor":"c1"}
+			}}}}`))
+		case "c1":
+			w.Write([]byte(`{"data":{"repositoryOwner":{"repositories":{
+				"nodes":[{"name":"c"}],
+				"pageInfo":{"hasNextPage":false,"endCursor":"c2"}
+			}}}}`))
+		}
+	})
+
+	fsys := New(opt, WithOwner("owner"), WithGraphQLBackend())
+
+	file, err := fsys.Open(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := file.(CursorDir)
+
+	entries, err := dir.ReadDir(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := entries[0].Info()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if repo := info.Sys().(*github.Repository); !repo.GetArchived() || repo.GetVisibility() != "public" {
+		t.Errorf("unexpected repository metadata: %v", repo)
+	}
+
+	if want := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC); !info.ModTime().Equal(want) {
+		t.Errorf("unexpected modification time: got %v, want %v", info.ModTime(), want)
+	}
+
+	// Repositories that were never pushed to fall back to the update time
+	if info, _ := entries[1].Info(); !info.ModTime().Equal(time.Date(2024, 2, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected modification time: %v", info.ModTime())
+	}
+
+	cursor := dir.Cursor()
+	file.Close()
+
+	file, err = fsys.OpenAtCursor(".", cursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	entries, err = file.(CursorDir).ReadDir(-1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 1 || entries[0].Name() != "c" {
+		t.Errorf("unexpected entries after resuming: %v", entries)
+	}
+
+	if want := []string{"", "c1"}; !slices.Equal(cursors, want) {
+		t.Errorf("unexpected pages requested: got %v, want %v", cursors, want)
+	}
+
+	if _, err := fs.ReadDir(New(opt, WithOwner("unknown"), WithGraphQLBackend()), "."); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected unknown owners not to exist, got %v", err)
+	}
+}
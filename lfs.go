@@ -0,0 +1,219 @@
+package githubfs
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const lfsPointerVersion = "version https://git-lfs.github.com/spec/v1"
+
+// lfsPointer is a Git LFS pointer file.
+//
+// See https://github.com/git-lfs/git-lfs/blob/main/docs/spec.md
+type lfsPointer struct {
+	oid  string
+	size int64
+}
+
+// parseLFSPointer parses content as a Git LFS pointer file.
+func parseLFSPointer(content string) (lfsPointer, bool) {
+	// Pointer files are always smaller than 1024 bytes.
+	if len(content) >= 1024 || !strings.HasPrefix(content, lfsPointerVersion+"\n") {
+		return lfsPointer{}, false
+	}
+
+	var p lfsPointer
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		key, value, _ := strings.Cut(scanner.Text(), " ")
+
+		switch key {
+		case "oid":
+			oid, ok := strings.CutPrefix(value, "sha256:")
+			if !ok {
+				return lfsPointer{}, false
+			}
+
+			p.oid = oid
+
+		case "size":
+			size, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return lfsPointer{}, false
+			}
+
+			p.size = size
+		}
+	}
+
+	if p.oid == "" {
+		return lfsPointer{}, false
+	}
+
+	return p, true
+}
+
+type lfsBatchRequest struct {
+	Operation string      `json:"operation"`
+	Transfers []string    `json:"transfers"`
+	Objects   []lfsObject `json:"objects"`
+}
+
+type lfsBatchResponse struct {
+	Objects []lfsObject `json:"objects"`
+}
+
+type lfsObject struct {
+	OID     string `json:"oid"`
+	Size    int64  `json:"size"`
+	Actions *struct {
+		Download *struct {
+			Href   string            `json:"href"`
+			Header map[string]string `json:"header"`
+		} `json:"download"`
+	} `json:"actions,omitempty"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// lfsEndpoint returns the LFS batch API endpoint of a repository.
+func (f *FS) lfsEndpoint(r ref) string {
+	u := *f.client.BaseURL
+
+	if u.Host == "api.github.com" {
+		u.Host = "github.com"
+	}
+
+	// GitHub Enterprise Server serves the API under /api/v3/
+	u.Path = strings.TrimSuffix(u.Path, "api/v3/")
+
+	return u.JoinPath(r.owner, r.repo+".git", "info/lfs/objects/batch").String()
+}
+
+// openLFSObject downloads the object a Git LFS pointer refers to.
+func (f *FS) openLFSObject(ctx context.Context, r ref, p lfsPointer) (io.ReadCloser, error) {
+	resp, err := f.downloadLFSObject(ctx, r, p, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
+
+// openLFSRange downloads n bytes of the object a Git LFS pointer refers to, starting at off.
+func (f *FS) openLFSRange(ctx context.Context, r ref, p lfsPointer, off int64, n int64) (io.ReadCloser, error) {
+	resp, err := f.downloadLFSObject(ctx, r, p, rangeHeader(off, n))
+	if err != nil {
+		return nil, err
+	}
+
+	return rangeBody(resp, off)
+}
+
+// downloadLFSObject requests the object a Git LFS pointer refers to from the storage host.
+func (f *FS) downloadLFSObject(ctx context.Context, r ref, p lfsPointer, header http.Header) (*http.Response, error) {
+	body := &lfsBatchRequest{
+		Operation: "download",
+		Transfers: []string{"basic"},
+		Objects:   []lfsObject{{OID: p.oid, Size: p.size}},
+	}
+
+	req, err := f.client.NewRequest(http.MethodPost, f.lfsEndpoint(r), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/vnd.git-lfs+json")
+	req.Header.Set("Content-Type", "application/vnd.git-lfs+json")
+
+	var batch lfsBatchResponse
+
+	_, err = f.client.Do(f.ctxFn(ctx), req, &batch)
+	if err := handleErr(err, "open", r.string()); err != nil {
+		return nil, err
+	}
+
+	if len(batch.Objects) == 0 {
+		return nil, fmt.Errorf("lfs: object %s missing from batch response", p.oid)
+	}
+
+	object := batch.Objects[0]
+
+	if object.Error != nil {
+		return nil, fmt.Errorf("lfs: object %s: %s (%d)", p.oid, object.Error.Message, object.Error.Code)
+	}
+
+	if object.Actions == nil || object.Actions.Download == nil {
+		return nil, fmt.Errorf("lfs: object %s has no download action", p.oid)
+	}
+
+	download, err := http.NewRequestWithContext(f.ctxFn(ctx), http.MethodGet, object.Actions.Download.Href, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, values := range header {
+		download.Header[key] = values
+	}
+
+	for key, value := range object.Actions.Download.Header {
+		download.Header.Set(key, value)
+	}
+
+	resp, err := f.lfsClient.Do(download)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		resp.Body.Close()
+
+		return nil, fmt.Errorf("lfs: downloading object %s: unexpected status %s", p.oid, resp.Status)
+	}
+
+	return resp, nil
+}
+
+// buildLFSClient returns the client downloading Git LFS objects.
+//
+// Download URLs usually point to a storage host authenticated by the action headers,
+// so requests are sent using the transport of the base client (including proxy and TLS options)
+// without GitHub credentials, but still count towards the limits of the filesystem.
+func (f *FS) buildLFSClient() *http.Client {
+	transport := f.baseClient.Client().Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	if f.timeout > 0 {
+		transport = &timeoutTransport{base: transport, timeout: f.timeout}
+	}
+
+	transport = &statsTransport{base: transport, stats: f.stats}
+
+	if f.budget != nil {
+		transport = &budgetTransport{base: transport, budget: f.budget}
+	}
+
+	if f.retry != nil {
+		transport = &retryTransport{base: transport, policy: f.retry.withDefaults()}
+	}
+
+	if f.userAgent != "" {
+		transport = &userAgentTransport{base: transport, userAgent: f.userAgent}
+	}
+
+	if f.limit != nil {
+		transport = &limitTransport{base: transport, sem: f.limit}
+	}
+
+	return &http.Client{Transport: transport}
+}
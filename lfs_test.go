@@ -0,0 +1,197 @@
+package githubfs
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v74/github"
+)
+
+const testLFSPointer = `version https://git-lfs.github.com/spec/v1
+oid sha256:4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393
+size 12345
+`
+
+func TestParseLFSPointer(t *testing.T) {
+	pointer, ok := parseLFSPointer(testLFSPointer)
+	if !ok {
+		t.Fatal("expected content to be parsed as an LFS pointer")
+	}
+
+	if pointer.oid != "4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393" || pointer.size != 12345 {
+		t.Errorf("unexpected pointer: %+v", pointer)
+	}
+
+	if _, ok := parseLFSPointer("# README\n"); ok {
+		t.Error("expected regular content not to be parsed as an LFS pointer")
+	}
+}
+
+func TestLFS(t *testing.T) {
+	mux, opt := setup(t)
+
+	mux.HandleFunc("GET /repos/owner/repo/contents/image.png", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") == mediaTypeRaw {
+			w.Write([]byte(testLFSPointer))
+
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]any{
+			"type":     "file",
+			"name":     "image.png",
+			"encoding": "base64",
+			"content":  base64.StdEncoding.EncodeToString([]byte(testLFSPointer)),
+			"size":     len(testLFSPointer),
+		})
+	})
+	mux.HandleFunc("POST /owner/repo.git/info/lfs/objects/batch", func(w http.ResponseWriter, r *http.Request) {
+		var req lfsBatchRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+
+		if req.Operation != "download" || len(req.Objects) != 1 {
+			t.Errorf("unexpected batch request: %+v", req)
+		}
+
+		w.Write([]byte(`{"objects":[{"oid":"` + req.Objects[0].OID + `","size":12345,"actions":{"download":{"href":"http://` + r.Host + `/objects/1","header":{"X-Token":"secret"}}}}]}`))
+	})
+	mux.HandleFunc("GET /objects/1", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Token") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		w.Write([]byte("binary content"))
+	})
+
+	fsys := New(opt, WithRepository("owner", "repo"), WithLFS())
+
+	content, err := fs.ReadFile(fsys, "image.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(content) != "binary content" {
+		t.Errorf("unexpected content: %q", content)
+	}
+
+	file, err := fsys.Open("image.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Size() != 12345 {
+		t.Errorf("expected size of the LFS object, got %d", info.Size())
+	}
+
+	io.Copy(io.Discard, file)
+}
+
+func TestLFSTransport(t *testing.T) {
+	mux := http.NewServeMux()
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("GET /repos/owner/repo/contents/image.png", fileHandler(testLFSPointer))
+	mux.HandleFunc("POST /owner/repo.git/info/lfs/objects/batch", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"objects":[{"actions":{"download":{"href":"http://` + r.Host + `/objects/1"}}}]}`))
+	})
+	mux.HandleFunc("GET /objects/1", func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			t.Errorf("credentials sent to the storage host: %q", auth)
+		}
+
+		w.Write([]byte("binary content"))
+	})
+
+	transport := &countingTransport{}
+
+	client := github.NewClient(&http.Client{Transport: transport})
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	fsys := New(WithClient(client), WithToken("token"), WithRepository("owner", "repo"), WithLFS(), WithRequestBudget(10))
+
+	content, err := fs.ReadFile(fsys, "image.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(content) != "binary content" {
+		t.Errorf("unexpected content: %q", content)
+	}
+
+	if used := fsys.RequestsUsed(); transport.requests != 3 || used != 3 {
+		t.Errorf("expected the download to use the configured transport and budget, got %d requests (%d counted)", transport.requests, used)
+	}
+}
+
+func TestLFSRangeReads(t *testing.T) {
+	mux, opt := setup(t)
+
+	object := strings.Repeat("x", 12340) + "trail"
+
+	mux.HandleFunc("GET /repos/owner/repo/contents/asset.bin", fileHandler(testLFSPointer))
+	mux.HandleFunc("POST /owner/repo.git/info/lfs/objects/batch", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"objects":[{"oid":"abc","size":12345,"actions":{"download":{"href":"http://` + r.Host + `/objects/1"}}}]}`))
+	})
+
+	var ranges []string
+
+	mux.HandleFunc("GET /objects/1", func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+
+		http.ServeContent(w, r, "asset.bin", time.Time{}, strings.NewReader(object))
+	})
+
+	file, err := New(opt, WithRepository("owner", "repo"), WithLFS()).Open("asset.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	p := make([]byte, 5)
+
+	if _, err := file.(io.ReaderAt).ReadAt(p, 12340); err != nil {
+		t.Fatal(err)
+	}
+
+	if string(p) != "trail" {
+		t.Errorf("unexpected content at offset: %q", p)
+	}
+
+	if _, err := file.(io.Seeker).Seek(-5, io.SeekEnd); err != nil {
+		t.Fatal(err)
+	}
+
+	tail, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(tail) != "trail" {
+		t.Errorf("unexpected tail: %q", tail)
+	}
+
+	if want := []string{"", "bytes=12340-12344", "bytes=12340-12344"}; !slices.Equal(ranges, want) {
+		t.Errorf("expected range requests, got %v", ranges)
+	}
+}
@@ -0,0 +1,22 @@
+package githubfs
+
+import (
+	"net/http"
+)
+
+// limitTransport limits the number of requests in flight (see [WithMaxConcurrency]).
+type limitTransport struct {
+	base http.RoundTripper
+	sem  chan struct{}
+}
+
+func (t *limitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	select {
+	case t.sem <- struct{}{}:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+	defer func() { <-t.sem }()
+
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,140 @@
+package githubfs
+
+import (
+	"context"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// treeModes lazily resolves the modes of entries in a directory using the Git Trees API.
+//
+// The Contents API does not return file modes, so the tree is only fetched when a mode is actually requested.
+type treeModes struct {
+	once  sync.Once
+	load  func() (map[string]string, error)
+	modes map[string]string
+}
+
+// treeModes returns a lazy mode resolver for the directory r points to.
+func (f *FS) treeModes(ctx context.Context, r ref, revision string) *treeModes {
+	return &treeModes{
+		load: func() (map[string]string, error) {
+			return f.dirModes(ctx, r, revision)
+		},
+	}
+}
+
+// dirModesTTL is how long modes of entries at branches are cached if [WithMetadataCache] is disabled,
+// so that resolvers created for every [FS.Stat] of a directory share a single request.
+const dirModesTTL = 10 * time.Second
+
+// dirModes returns the modes of the entries of the directory r points to.
+//
+// Modes are taken from a cached recursive tree containing the directory (e.g. fetched by a walk) if there is one.
+// Otherwise the tree of the directory is fetched and cached like listings (see [WithMetadataCache] and [dirModesTTL]).
+func (f *FS) dirModes(ctx context.Context, r ref, revision string) (map[string]string, error) {
+	if modes, ok := f.cachedModes(r, revision); ok {
+		return modes, nil
+	}
+
+	key := "modes " + r.string() + "@" + revision
+
+	cache := f.metadata
+	if cache == nil {
+		cache = f.modes
+	}
+
+	if v, ok := cache.get(key); ok {
+		return v.(map[string]string), nil
+	}
+
+	v, err, _ := f.calls.Do(key, func() (any, error) {
+		tree, err := f.getTree(ctx, "stat", r, revision, false)
+		if err != nil {
+			return nil, err
+		}
+
+		modes := make(map[string]string, len(tree.Entries))
+		for _, entry := range tree.Entries {
+			modes[entry.GetPath()] = entry.GetMode()
+		}
+
+		cache.set(key, modes, isCommitSHA(revision) && cache == f.metadata)
+
+		return modes, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return v.(map[string]string), nil
+}
+
+// cachedModes returns the modes of the entries of the directory r points to
+// from a cached recursive tree of the directory or one of its parents.
+func (f *FS) cachedModes(r ref, revision string) (map[string]string, bool) {
+	for dir := r; ; dir = dir.parent() {
+		if tree := f.cachedTree(dir, revision); tree != nil {
+			rel := "."
+			if r.path != dir.path {
+				rel = strings.TrimPrefix(r.path, strings.TrimPrefix(dir.path+"/", "/"))
+			}
+
+			modes := make(map[string]string)
+			for _, entry := range tree.Entries {
+				if path.Dir(entry.GetPath()) == rel {
+					modes[path.Base(entry.GetPath())] = entry.GetMode()
+				}
+			}
+
+			return modes, true
+		}
+
+		if dir.path == "" {
+			return nil, false
+		}
+	}
+}
+
+// mode returns the mode of a directory entry.
+//
+// Falls back to 0o644 if the tree cannot be loaded.
+func (m *treeModes) mode(name string) fs.FileMode {
+	if m == nil {
+		return 0o644
+	}
+
+	m.once.Do(func() {
+		m.modes, _ = m.load()
+	})
+
+	return gitMode(m.modes[name])
+}
+
+// gitMode returns the file mode of a git tree entry mode (of a file).
+func gitMode(mode string) fs.FileMode {
+	switch mode {
+	case "100755":
+		return 0o755
+	case "120000":
+		return fs.ModeSymlink | 0o777
+	default:
+		return 0o644
+	}
+}
+
+// treeish returns a tree-ish expression for a path at a git reference.
+func treeish(revision string, p string) string {
+	if revision == "" {
+		revision = "HEAD"
+	}
+
+	if p == "" || p == "." {
+		return revision
+	}
+
+	return revision + ":" + path.Clean(p)
+}
@@ -0,0 +1,113 @@
+package githubfs
+
+import (
+	"io/fs"
+	"net/http"
+	"testing"
+)
+
+func TestFileModes(t *testing.T) {
+	mux, opt := setup(t)
+
+	var trees int
+
+	mux.HandleFunc("GET /repos/owner/repo/contents/bin", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"type":"file","name":"run.sh","size":3},{"type":"file","name":"README","size":3},{"type":"symlink","name":"link","size":6}]`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/contents/bin/run.sh", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"type":"file","name":"run.sh","encoding":"base64","content":"Li4u","size":3}`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/git/trees/{sha...}", func(w http.ResponseWriter, r *http.Request) {
+		trees++
+
+		if got, want := r.PathValue("sha"), "HEAD:bin"; got != want {
+			t.Errorf("unexpected tree-ish: got %q, want %q", got, want)
+		}
+
+		w.Write([]byte(`{"tree":[{"path":"run.sh","mode":"100755","type":"blob"},{"path":"README","mode":"100644","type":"blob"},{"path":"link","mode":"120000","type":"blob"}]}`))
+	})
+
+	fsys := New(opt, WithRepository("owner", "repo"))
+
+	entries, err := fs.ReadDir(fsys, "bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if trees != 0 {
+		t.Error("expected modes to be resolved lazily")
+	}
+
+	want := map[string]fs.FileMode{"run.sh": 0o755, "README": 0o644, "link": fs.ModeSymlink | 0o777}
+
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := entry.Type(); got != want[entry.Name()].Type() {
+			t.Errorf("unexpected type for %s: got %v, want %v", entry.Name(), got, want[entry.Name()].Type())
+		}
+
+		if got := info.Mode(); got != want[entry.Name()] {
+			t.Errorf("unexpected mode for %s: got %v, want %v", entry.Name(), got, want[entry.Name()])
+		}
+	}
+
+	if trees != 1 {
+		t.Errorf("expected the tree to be fetched once per directory, got %d", trees)
+	}
+
+	// Every Stat creates a new resolver: modes are shared between them.
+	for range 2 {
+		info, err := fs.Stat(fsys, "bin/run.sh")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := info.Mode(); got != 0o755 {
+			t.Errorf("unexpected mode: got %v, want %v", got, fs.FileMode(0o755))
+		}
+	}
+
+	if trees != 1 {
+		t.Errorf("expected modes to be cached, got %d tree requests", trees)
+	}
+}
+
+func TestFileModesFromCachedTree(t *testing.T) {
+	mux, opt := setup(t)
+
+	const sha = "0123456789abcdef0123456789abcdef01234567"
+
+	var trees int
+
+	mux.HandleFunc("GET /repos/owner/repo/git/trees/{sha...}", func(w http.ResponseWriter, r *http.Request) {
+		trees++
+
+		w.Write([]byte(`{"tree":[{"path":"bin","mode":"040000","type":"tree"},{"path":"bin/run.sh","mode":"100755","type":"blob","size":3}]}`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/contents/bin", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"type":"file","name":"run.sh","size":3}]`))
+	})
+
+	fsys := New(opt, WithRepository("owner", "repo"), WithRef(sha))
+
+	if err := WalkTree(fsys, ".", func(path string, d fs.DirEntry, err error) error { return err }); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := fs.Stat(fsys, "bin/run.sh")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := info.Mode(); got != 0o755 {
+		t.Errorf("unexpected mode: got %v, want %v", got, fs.FileMode(0o755))
+	}
+
+	if trees != 1 {
+		t.Errorf("expected modes to be taken from the cached tree, got %d tree requests", trees)
+	}
+}
@@ -0,0 +1,131 @@
+package githubfs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v74/github"
+)
+
+// modTime lazily resolves the modification time of an entry:
+// the date of the commit the repository is read at (or the last commit touching the entry, see [WithCommitModTimes]).
+//
+// The Contents API does not return modification times, so the commit is only fetched when a time is actually requested.
+type modTime struct {
+	once sync.Once
+	load func() (time.Time, error)
+	t    time.Time
+}
+
+// modTime returns a lazy modification time resolver for the entry r points to at a git reference.
+func (f *FS) modTime(ctx context.Context, r ref, revision string) *modTime {
+	if !f.commitModTimes {
+		r = ref{owner: r.owner, repo: r.repo}
+	}
+
+	return &modTime{
+		load: func() (time.Time, error) {
+			return f.commitTime(ctx, r, revision)
+		},
+	}
+}
+
+// fixedModTime returns a modification time known upfront (e.g. the time a repository was last pushed to).
+func fixedModTime(t time.Time) *modTime {
+	return &modTime{
+		load: func() (time.Time, error) {
+			return t, nil
+		},
+	}
+}
+
+// time returns the modification time.
+//
+// Falls back to the zero time if the commit cannot be loaded.
+func (m *modTime) time() time.Time {
+	if m == nil {
+		return time.Time{}
+	}
+
+	m.once.Do(func() {
+		m.t, _ = m.load()
+	})
+
+	return m.t
+}
+
+// branchTimesTTL is how long commit times at branches are cached if [WithMetadataCache] is disabled:
+// long enough for a walk or an HTTP request to stat the same entries repeatedly, short enough to pick up new commits.
+const branchTimesTTL = 10 * time.Second
+
+// commitTime returns the committer date of the last commit touching the entry r points to at a git reference.
+//
+// Times at a commit SHA never change, so they are cached for the lifetime of the filesystem.
+// Times at a branch (or the default branch) move with new commits: they are only cached for the TTL of [WithMetadataCache]
+// (or briefly, see [branchTimesTTL]).
+func (f *FS) commitTime(ctx context.Context, r ref, revision string) (time.Time, error) {
+	key := r.string() + "@" + revision
+	immutable := isCommitSHA(revision)
+
+	times := f.metadata
+	if times == nil {
+		times = f.times
+	}
+
+	if immutable {
+		f.revisions.mu.Lock()
+		t, ok := f.revisions.times[key]
+		f.revisions.mu.Unlock()
+
+		if ok {
+			return t, nil
+		}
+	} else if v, ok := times.get("time " + key); ok {
+		return v.(time.Time), nil
+	}
+
+	// Concurrent lookups of the same entry share a single request.
+	v, err, _ := f.calls.Do("time "+key, func() (any, error) {
+		return f.fetchCommitTime(ctx, r, revision)
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	t := v.(time.Time)
+
+	if !immutable {
+		times.set("time "+key, t, false)
+
+		return t, nil
+	}
+
+	f.revisions.mu.Lock()
+	f.revisions.times[key] = t
+	f.revisions.mu.Unlock()
+
+	return t, nil
+}
+
+// fetchCommitTime fetches the committer date of the last commit touching the entry r points to at a git reference.
+func (f *FS) fetchCommitTime(ctx context.Context, r ref, revision string) (time.Time, error) {
+	var t time.Time
+
+	opts := &github.CommitsListOptions{
+		SHA:         revision,
+		Path:        r.path,
+		ListOptions: github.ListOptions{PerPage: 1},
+	}
+
+	commits, _, err := f.client.Repositories.ListCommits(f.ctxFn(ctx), r.owner, r.repo, opts)
+	if err := handleErr(err, "stat", r.string()); err != nil {
+		return time.Time{}, err
+	}
+
+	if len(commits) > 0 {
+		t = commits[0].GetCommit().GetCommitter().GetDate().Time
+	}
+
+	return t, nil
+}
@@ -0,0 +1,160 @@
+package githubfs
+
+import (
+	"fmt"
+	"io/fs"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCommitModTimes(t *testing.T) {
+	mux, opt := setup(t)
+
+	dates := map[string]string{
+		"":               "2024-03-01T00:00:00Z",
+		"docs/README.md": "2024-01-01T00:00:00Z",
+		"docs/guide.md":  "2024-02-01T00:00:00Z",
+	}
+
+	var commits int
+
+	const sha = "0123456789abcdef0123456789abcdef01234567"
+
+	mux.HandleFunc("GET /repos/owner/repo/contents/docs", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"type":"file","name":"README.md","size":5},{"type":"file","name":"guide.md","size":5}]`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/commits", func(w http.ResponseWriter, r *http.Request) {
+		commits++
+
+		fmt.Fprintf(w, `[{"sha":"abc","commit":{"committer":{"date":%q}}}]`, dates[r.URL.Query().Get("path")])
+	})
+
+	tests := []struct {
+		opts     []Option
+		want     map[string]string
+		requests int
+	}{
+		{
+			want:     map[string]string{"README.md": dates[""], "guide.md": dates[""]},
+			requests: 1,
+		},
+		{
+			opts:     []Option{WithCommitModTimes()},
+			want:     map[string]string{"README.md": dates["docs/README.md"], "guide.md": dates["docs/guide.md"]},
+			requests: 2,
+		},
+	}
+
+	for _, test := range tests {
+		commits = 0
+
+		fsys := New(append([]Option{opt, WithRepository("owner", "repo"), WithRef(sha)}, test.opts...)...)
+
+		entries, err := fs.ReadDir(fsys, "docs")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if commits != 0 {
+			t.Error("expected modification times to be resolved lazily")
+		}
+
+		for range 2 {
+			for _, entry := range entries {
+				info, err := entry.Info()
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if got, want := info.ModTime().Format(time.RFC3339), test.want[entry.Name()]; got != want {
+					t.Errorf("unexpected modification time of %q: got %s, want %s", entry.Name(), got, want)
+				}
+			}
+		}
+
+		if commits != test.requests {
+			t.Errorf("unexpected number of commit requests: got %d, want %d", commits, test.requests)
+		}
+	}
+}
+
+func TestBranchModTimes(t *testing.T) {
+	mux, opt := setup(t)
+
+	date := "2024-01-01T00:00:00Z"
+	requests := 0
+
+	mux.HandleFunc("GET /repos/owner/repo/contents/README.md", fileHandler("hello"))
+	mux.HandleFunc("GET /repos/owner/repo/commits", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		fmt.Fprintf(w, `[{"sha":"abc","commit":{"committer":{"date":%q}}}]`, date)
+	})
+
+	fsys := New(opt, WithRepository("owner", "repo"))
+
+	now := time.Now()
+	fsys.times.now = func() time.Time { return now }
+
+	for i, want := range []string{"2024-01-01T00:00:00Z", "2024-02-01T00:00:00Z"} {
+		date = want
+
+		// Times at branches are cached briefly even without a metadata cache.
+		for range 2 {
+			f, err := fsys.Open("README.md")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			info, err := f.Stat()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			f.Close()
+
+			if got := info.ModTime().Format(time.RFC3339); got != want {
+				t.Errorf("expected modification time to follow the branch: got %s, want %s", got, want)
+			}
+		}
+
+		if requests != i+1 {
+			t.Errorf("expected a single commit request per file within the TTL, got %d", requests)
+		}
+
+		now = now.Add(branchTimesTTL)
+	}
+}
+
+func TestRepositoryModTimes(t *testing.T) {
+	mux, opt := setup(t)
+
+	mux.HandleFunc("GET /users/owner/repos", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[
+			{"name":"active","pushed_at":"2024-05-01T00:00:00Z","updated_at":"2024-01-01T00:00:00Z"},
+			{"name":"empty","updated_at":"2024-02-01T00:00:00Z"}
+		]`))
+	})
+
+	entries, err := fs.ReadDir(New(opt, WithOwner("owner")), ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"active": "2024-05-01T00:00:00Z",
+		"empty":  "2024-02-01T00:00:00Z",
+	}
+
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := info.ModTime().Format(time.RFC3339); got != want[entry.Name()] {
+			t.Errorf("unexpected modification time of %q: got %s, want %s", entry.Name(), got, want[entry.Name()])
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package githubfs
+
+import (
+	"io/fs"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// MultiHostFS is a filesystem addressing repositories on multiple GitHub hosts (e.g. github.com and GitHub Enterprise Server).
+//
+// Paths are prefixed with the host: "<host>/<owner>/<repo>/<path>".
+type MultiHostFS struct {
+	hosts map[string]*FS
+}
+
+// NewMultiHost returns a filesystem serving each host from its own filesystem.
+//
+// Filesystems should not be rooted at an owner or repository,
+// and should be configured to talk to their host (see [WithBaseURL]) with the credentials they require.
+func NewMultiHost(hosts map[string]*FS) *MultiHostFS {
+	return &MultiHostFS{hosts: maps.Clone(hosts)}
+}
+
+// Open implements the [fs.FS] interface.
+func (m *MultiHostFS) Open(name string) (fs.File, error) {
+	fsys, rest, err := m.route("open", name)
+	if err != nil {
+		return nil, err
+	}
+
+	if fsys == nil {
+		entries := make([]*dirEntry, 0, len(m.hosts))
+		for _, host := range slices.Sorted(maps.Keys(m.hosts)) {
+			entries = append(entries, &dirEntry{name: host, isDir: true})
+		}
+
+		return &dir{name: ".", entries: entries}, nil
+	}
+
+	return fsys.Open(rest)
+}
+
+// Stat implements the [fs.StatFS] interface.
+func (m *MultiHostFS) Stat(name string) (fs.FileInfo, error) {
+	fsys, rest, err := m.route("stat", name)
+	if err != nil {
+		return nil, err
+	}
+
+	if fsys == nil || rest == "." {
+		return &fileInfo{name: name, isDir: true}, nil
+	}
+
+	return fsys.Stat(rest)
+}
+
+// Host returns the filesystem serving a host.
+func (m *MultiHostFS) Host(host string) (*FS, bool) {
+	fsys, ok := m.hosts[host]
+
+	return fsys, ok
+}
+
+// route returns the filesystem serving a path and the path relative to it.
+//
+// Returns a nil filesystem for the root.
+func (m *MultiHostFS) route(op string, name string) (*FS, string, error) {
+	if !fs.ValidPath(name) {
+		return nil, "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+
+	if name == "." {
+		return nil, "", nil
+	}
+
+	host, rest, ok := strings.Cut(name, "/")
+	if !ok {
+		rest = "."
+	}
+
+	fsys, ok := m.hosts[host]
+	if !ok {
+		return nil, "", &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+
+	return fsys, rest, nil
+}
@@ -0,0 +1,71 @@
+package githubfs
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"testing"
+)
+
+// repoHandler serves a repository containing a single README.md file.
+func repoHandler(content string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("path") == "" {
+			w.Write([]byte(`[{"type":"file","name":"README.md","path":"README.md","size":7}]`))
+
+			return
+		}
+
+		fileHandler(content)(w, r)
+	}
+}
+
+func TestMultiHostFS(t *testing.T) {
+	public, publicOpt := setup(t)
+	public.HandleFunc("GET /repos/owner/repo/contents/{path...}", repoHandler("public"))
+
+	enterprise, enterpriseOpt := setup(t)
+	enterprise.HandleFunc("GET /repos/owner/repo/contents/{path...}", repoHandler("enterprise"))
+
+	fsys := NewMultiHost(map[string]*FS{
+		"github.com":         New(publicOpt),
+		"github.example.com": New(enterpriseOpt),
+	})
+
+	for host, want := range map[string]string{"github.com": "public", "github.example.com": "enterprise"} {
+		content, err := fs.ReadFile(fsys, host+"/owner/repo/README.md")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(content) != want {
+			t.Errorf("unexpected content from %s: %q", host, content)
+		}
+
+		info, err := fs.Stat(fsys, host+"/owner/repo/README.md")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if info.Name() != "README.md" {
+			t.Errorf("unexpected name: %q", info.Name())
+		}
+	}
+
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 2 || entries[0].Name() != "github.com" || entries[1].Name() != "github.example.com" {
+		t.Errorf("unexpected hosts: %v", entries)
+	}
+
+	if info, err := fs.Stat(fsys, "github.com"); err != nil || !info.IsDir() {
+		t.Errorf("expected hosts to be directories: %v, %v", info, err)
+	}
+
+	if _, err := fs.ReadFile(fsys, "gitlab.com/owner/repo/README.md"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected unknown hosts not to exist, got %v", err)
+	}
+}
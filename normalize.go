@@ -0,0 +1,128 @@
+package githubfs
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+)
+
+// LineEnding is a line ending style text files are normalized to (see [WithLineEndingNormalization]).
+type LineEnding int
+
+const (
+	// LF normalizes line endings to "\n".
+	LF LineEnding = iota + 1
+
+	// CRLF normalizes line endings to "\r\n".
+	CRLF
+)
+
+// binarySniffLen is the number of bytes inspected to detect binary content (the same heuristic git uses).
+const binarySniffLen = 8000
+
+// isBinary reports whether content looks like binary data.
+func isBinary(content []byte) bool {
+	return bytes.IndexByte(content[:min(len(content), binarySniffLen)], 0) >= 0
+}
+
+// normalize converts the line endings of text content to the configured style.
+//
+// Binary content is returned unchanged.
+func (f *FS) normalize(content []byte) []byte {
+	if f.lineEnding == 0 || isBinary(content) {
+		return content
+	}
+
+	content = bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n"))
+
+	if f.lineEnding == CRLF {
+		content = bytes.ReplaceAll(content, []byte("\n"), []byte("\r\n"))
+	}
+
+	return content
+}
+
+// normalizeFile buffers the content of a file and normalizes its line endings.
+//
+// Content is buffered in memory up to the limits configured by [WithMemoryLimit] and in a temporary file beyond them.
+// The size of the file is updated to reflect the normalized content.
+func (f *FS) normalizeFile(file *file) (*file, error) {
+	if f.lineEnding == 0 {
+		return file, nil
+	}
+
+	content := bufio.NewReader(file.content)
+	head, _ := content.Peek(binarySniffLen)
+
+	buf := newSpool(f.memory)
+
+	var err error
+	if isBinary(head) {
+		_, err = io.Copy(buf, content)
+	} else {
+		err = f.normalizeTo(buf, content)
+	}
+
+	file.content.Close()
+	if err != nil {
+		buf.discard()
+
+		return nil, err
+	}
+
+	spooled, err := buf.buffer()
+	if err != nil {
+		return nil, err
+	}
+
+	file.size = spooled.size
+	file.content = spooled
+	file.reopen = nil
+	file.openRange = nil
+
+	return file, nil
+}
+
+// normalizeTo copies text content to w converting its line endings to the configured style
+// (the same way [FS.normalize] does, without holding the entire content in memory).
+func (f *FS) normalizeTo(w io.Writer, r *bufio.Reader) error {
+	newline := []byte("\n")
+	if f.lineEnding == CRLF {
+		newline = []byte("\r\n")
+	}
+
+	for {
+		line, err := r.ReadSlice('\n')
+
+		switch {
+		case err == nil:
+			line = bytes.TrimSuffix(line[:len(line)-1], []byte("\r"))
+
+			if _, err := w.Write(line); err != nil {
+				return err
+			}
+
+			if _, err := w.Write(newline); err != nil {
+				return err
+			}
+
+			continue
+
+		case err == bufio.ErrBufferFull && len(line) > 1 && line[len(line)-1] == '\r':
+			// Keep a trailing "\r" for the next read: it may be followed by "\n".
+			line = line[:len(line)-1]
+			r.UnreadByte()
+
+		case err != bufio.ErrBufferFull && err != io.EOF:
+			return err
+		}
+
+		if _, err := w.Write(line); err != nil {
+			return err
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package githubfs
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLineEndingNormalization(t *testing.T) {
+	mux, opt := setup(t)
+
+	mux.HandleFunc("GET /repos/owner/repo/contents/windows.txt", fileHandler("a\r\nb\r\n"))
+	mux.HandleFunc("GET /repos/owner/repo/contents/unix.txt", fileHandler("a\nb\n"))
+	mux.HandleFunc("GET /repos/owner/repo/contents/image.bin", fileHandler("\x00\r\n\x01\n"))
+
+	tests := []struct {
+		lineEnding LineEnding
+		name       string
+		want       string
+	}{
+		{LF, "windows.txt", "a\nb\n"},
+		{LF, "unix.txt", "a\nb\n"},
+		{LF, "image.bin", "\x00\r\n\x01\n"},
+		{CRLF, "windows.txt", "a\r\nb\r\n"},
+		{CRLF, "unix.txt", "a\r\nb\r\n"},
+		{CRLF, "image.bin", "\x00\r\n\x01\n"},
+	}
+
+	for _, test := range tests {
+		fsys := New(opt, WithRepository("owner", "repo"), WithLineEndingNormalization(test.lineEnding))
+
+		content, err := fs.ReadFile(fsys, test.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(content) != test.want {
+			t.Errorf("ReadFile(%q): unexpected content: got %q, want %q", test.name, content, test.want)
+		}
+
+		file, err := fsys.Open(test.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		content, err = io.ReadAll(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(content) != test.want {
+			t.Errorf("Open(%q): unexpected content: got %q, want %q", test.name, content, test.want)
+		}
+
+		info, err := file.Stat()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got, want := info.Size(), int64(len(test.want)); got != want {
+			t.Errorf("Open(%q): unexpected size: got %d, want %d", test.name, got, want)
+		}
+
+		file.Close()
+	}
+}
+
+func TestNormalizeTo(t *testing.T) {
+	// Lines longer than the read buffer, with "\r\n" split across reads
+	content := strings.Repeat("x", 15) + "\r\n" + strings.Repeat("y", 40) + "\r\n\rz"
+
+	tests := []struct {
+		lineEnding LineEnding
+		want       string
+	}{
+		{LF, strings.Repeat("x", 15) + "\n" + strings.Repeat("y", 40) + "\n\rz"},
+		{CRLF, strings.Repeat("x", 15) + "\r\n" + strings.Repeat("y", 40) + "\r\n\rz"},
+	}
+
+	for _, test := range tests {
+		f := &FS{lineEnding: test.lineEnding}
+
+		var buf bytes.Buffer
+
+		if err := f.normalizeTo(&buf, bufio.NewReaderSize(strings.NewReader(content), 16)); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := buf.String(); got != test.want {
+			t.Errorf("unexpected content: got %q, want %q", got, test.want)
+		}
+
+		if got, want := buf.String(), string(f.normalize([]byte(content))); got != want {
+			t.Errorf("expected the same result as normalize: got %q, want %q", got, want)
+		}
+	}
+}
+
+func TestWithMemoryLimit(t *testing.T) {
+	mux, opt := setup(t)
+
+	mux.HandleFunc("GET /repos/owner/repo/contents/windows.txt", fileHandler("a\r\nb\r\n"))
+
+	fsys := New(opt, WithRepository("owner", "repo"), WithLineEndingNormalization(LF), WithMemoryLimit(2, 0))
+
+	f, err := fsys.Open("windows.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spooled, ok := f.(*file).content.(*spooled)
+	if !ok {
+		t.Fatalf("expected content to be spooled, got %T", f.(*file).content)
+	}
+
+	temp, ok := spooled.reader.(*os.File)
+	if !ok {
+		t.Fatalf("expected content to be spilled to a temporary file, got %T", spooled.reader)
+	}
+
+	content, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(content) != "a\nb\n" {
+		t.Errorf("unexpected content: %q", content)
+	}
+
+	f.Close()
+
+	if _, err := os.Stat(temp.Name()); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected the temporary file to be removed: %v", err)
+	}
+
+	if used := fsys.memory.used.Load(); used != 0 {
+		t.Errorf("expected memory to be released: %d bytes used", used)
+	}
+}
@@ -0,0 +1,98 @@
+package githubfs
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/google/go-github/v74/github"
+)
+
+// Note returns the git note attached to a commit of the repository the filesystem is rooted at.
+//
+// notesRef is the name of the notes reference under refs/notes (defaults to "commits", the default of git notes).
+func (f *FS) Note(ctx context.Context, notesRef string, commit string) ([]byte, error) {
+	if notesRef == "" {
+		notesRef = "commits"
+	}
+
+	r, err := f.repository("note")
+	if err != nil {
+		return nil, err
+	}
+
+	p := r.string() + "@refs/notes/" + notesRef
+
+	gitRef, _, err := f.client.Git.GetRef(f.ctxFn(ctx), r.owner, r.repo, "notes/"+notesRef)
+	if err := handleErr(err, "note", p); err != nil {
+		return nil, err
+	}
+
+	notesCommit, _, err := f.client.Git.GetCommit(f.ctxFn(ctx), r.owner, r.repo, gitRef.GetObject().GetSHA())
+	if err := handleErr(err, "note", p); err != nil {
+		return nil, err
+	}
+
+	tree, _, err := f.client.Git.GetTree(f.ctxFn(ctx), r.owner, r.repo, notesCommit.GetTree().GetSHA(), true)
+	if err := handleErr(err, "note", p); err != nil {
+		return nil, err
+	}
+
+	for _, entry := range tree.Entries {
+		// Notes may be stored in fan-out directories (e.g. "ab/cdef...").
+		if entry.GetType() != "blob" || strings.ReplaceAll(entry.GetPath(), "/", "") != commit {
+			continue
+		}
+
+		content, _, err := f.client.Git.GetBlobRaw(f.ctxFn(ctx), r.owner, r.repo, entry.GetSHA())
+		if err := handleErr(err, "note", p); err != nil {
+			return nil, err
+		}
+
+		return content, nil
+	}
+
+	return nil, &fs.PathError{Op: "note", Path: p, Err: fmt.Errorf("no note for commit %s: %w", commit, fs.ErrNotExist)}
+}
+
+// TagVerification returns the signature verification status of a tag
+// of the repository the filesystem is rooted at, as reported by GitHub.
+//
+// Lightweight tags cannot be signed: they are reported as unverified with the reason "unsigned".
+func (f *FS) TagVerification(ctx context.Context, tag string) (*github.SignatureVerification, error) {
+	r, err := f.repository("tag")
+	if err != nil {
+		return nil, err
+	}
+
+	p := r.string() + "@refs/tags/" + tag
+
+	gitRef, _, err := f.client.Git.GetRef(f.ctxFn(ctx), r.owner, r.repo, "tags/"+tag)
+	if err := handleErr(err, "tag", p); err != nil {
+		return nil, err
+	}
+
+	if gitRef.GetObject().GetType() != "tag" {
+		return &github.SignatureVerification{
+			Verified: github.Ptr(false),
+			Reason:   github.Ptr("unsigned"),
+		}, nil
+	}
+
+	annotated, _, err := f.client.Git.GetTag(f.ctxFn(ctx), r.owner, r.repo, gitRef.GetObject().GetSHA())
+	if err := handleErr(err, "tag", p); err != nil {
+		return nil, err
+	}
+
+	return annotated.GetVerification(), nil
+}
+
+// repository returns the ref of the repository the filesystem is rooted at.
+func (f *FS) repository(op string) (ref, error) {
+	if f.ref.owner == "" || f.ref.repo == "" {
+		return ref{}, &fs.PathError{Op: op, Path: f.ref.string(), Err: fmt.Errorf("filesystem is not rooted in a repository: %w", fs.ErrInvalid)}
+	}
+
+	return ref{owner: f.ref.owner, repo: f.ref.repo}, nil
+}
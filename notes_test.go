@@ -0,0 +1,81 @@
+package githubfs
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"testing"
+)
+
+func TestNote(t *testing.T) {
+	mux, opt := setup(t)
+
+	mux.HandleFunc("GET /repos/owner/repo/git/ref/notes/commits", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ref":"refs/notes/commits","object":{"type":"commit","sha":"n1"}}`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/git/commits/n1", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"sha":"n1","tree":{"sha":"t1"}}`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/git/trees/t1", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"sha":"t1","tree":[
+			{"path":"ab","type":"tree","sha":"t2"},
+			{"path":"ab/cdef","type":"blob","sha":"b1"}
+		]}`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/git/blobs/b1", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("Reviewed-by: someone"))
+	})
+
+	fsys := New(opt, WithRepository("owner", "repo"))
+
+	note, err := fsys.Note(t.Context(), "", "abcdef")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(note) != "Reviewed-by: someone" {
+		t.Errorf("unexpected note: %q", note)
+	}
+
+	if _, err := fsys.Note(t.Context(), "", "012345"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected missing note to not exist, got %v", err)
+	}
+
+	if _, err := New(opt, WithOwner("owner")).Note(t.Context(), "", "abcdef"); !errors.Is(err, fs.ErrInvalid) {
+		t.Errorf("expected owner-level filesystem to be rejected, got %v", err)
+	}
+}
+
+func TestTagVerification(t *testing.T) {
+	mux, opt := setup(t)
+
+	mux.HandleFunc("GET /repos/owner/repo/git/ref/tags/v1.0.0", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ref":"refs/tags/v1.0.0","object":{"type":"tag","sha":"t1"}}`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/git/tags/t1", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"sha":"t1","tag":"v1.0.0","verification":{"verified":true,"reason":"valid","signature":"-----BEGIN PGP SIGNATURE-----"}}`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/git/ref/tags/light", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ref":"refs/tags/light","object":{"type":"commit","sha":"c1"}}`))
+	})
+
+	fsys := New(opt, WithRepository("owner", "repo"))
+
+	verification, err := fsys.TagVerification(t.Context(), "v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !verification.GetVerified() || verification.GetReason() != "valid" {
+		t.Errorf("unexpected verification: %+v", verification)
+	}
+
+	verification, err = fsys.TagVerification(t.Context(), "light")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if verification.GetVerified() || verification.GetReason() != "unsigned" {
+		t.Errorf("unexpected verification of lightweight tag: %+v", verification)
+	}
+}
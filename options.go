@@ -1,9 +1,23 @@
 package githubfs
 
 import (
+	"cmp"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/go-github/v74/github"
+	"golang.org/x/oauth2"
 )
 
 // ClientOption configures the filesystem using the functional options paradigm popularized by Rob Pike and Dave Cheney.
@@ -17,18 +31,18 @@ import (
 // [Functional options for friendly APIs]: https://dave.cheney.net/2014/10/17/functional-options-for-friendly-apis.
 // [Functional options on steroids]: https://sagikazarmark.com/blog/posts/functional-options-on-steroids/
 type Option interface {
-	apply(c *fsys)
+	apply(c *FS)
 }
 
-type optionFunc func(*fsys)
+type optionFunc func(*FS)
 
-func (fn optionFunc) apply(f *fsys) {
+func (fn optionFunc) apply(f *FS) {
 	fn(f)
 }
 
 type options []Option
 
-func (o options) apply(f *fsys) {
+func (o options) apply(f *FS) {
 	for _, opt := range o {
 		opt.apply(f)
 	}
@@ -36,7 +50,7 @@ func (o options) apply(f *fsys) {
 
 // WithOwner configures the owner.
 func WithOwner(owner string) Option {
-	return optionFunc(func(f *fsys) {
+	return optionFunc(func(f *FS) {
 		if owner == "" {
 			return
 		}
@@ -47,7 +61,7 @@ func WithOwner(owner string) Option {
 
 // WithRepository configures the repository.
 func WithRepository(owner string, repo string) Option {
-	return optionFunc(func(f *fsys) {
+	return optionFunc(func(f *FS) {
 		if owner != "" {
 			f.ref.owner = owner
 		}
@@ -58,23 +72,586 @@ func WithRepository(owner string, repo string) Option {
 	})
 }
 
+// WithRef configures the git reference (branch, tag or commit SHA) to read repositories at.
+//
+// Defaults to the default branch of each repository.
+func WithRef(ref string) Option {
+	return optionFunc(func(f *FS) {
+		f.revision = ref
+	})
+}
+
+// WithRefAtTime configures the filesystem to read repositories as they were at the given time.
+//
+// The snapshot is the last commit before t on the configured ref (see [WithRef]) or the default branch.
+func WithRefAtTime(t time.Time) Option {
+	return optionFunc(func(f *FS) {
+		f.at = t
+	})
+}
+
+// WithLFS enables transparently resolving Git LFS pointer files to the objects they point to.
+func WithLFS() Option {
+	return optionFunc(func(f *FS) {
+		f.lfs = true
+	})
+}
+
+// WithRawBackend configures the filesystem to read file content from raw.githubusercontent.com
+// instead of the GitHub API, so that reading files of public repositories does not consume the API quota.
+// On GitHub Enterprise Server (see [WithBaseURL]), content is read from the "/raw/" path of the API host.
+//
+// Directory listings still use the API.
+// Credentials configured with [WithTokenSource] or [WithTokenFunc] are only sent to the API host.
+func WithRawBackend() Option {
+	return optionFunc(func(f *FS) {
+		f.rawBackend = true
+	})
+}
+
+// WithSkipInaccessible makes [FS.ReadDir] return errors matching [fs.SkipDir] (along with the cause,
+// e.g. [fs.ErrPermission]) for directories that cannot be read
+// (because they do not exist or access is denied, e.g. due to fine-grained token restrictions).
+//
+// [Walk], [WalkTree] and [WalkDirConcurrent] skip these directories (below the root of the walk)
+// instead of aborting the entire traversal when the callback returns the error.
+// [fs.WalkDir] only skips a directory if the callback returns [fs.SkipDir] itself
+// (e.g. after checking the error with [errors.Is]).
+func WithSkipInaccessible() Option {
+	return optionFunc(func(f *FS) {
+		f.skipInaccessible = true
+	})
+}
+
+// WithLineEndingNormalization converts line endings of text files to the given style when they are read.
+//
+// Files containing NUL bytes are considered binary and are served as is.
+// Normalized files are buffered (see [WithMemoryLimit]).
+func WithLineEndingNormalization(le LineEnding) Option {
+	return optionFunc(func(f *FS) {
+		if le != LF && le != CRLF {
+			f.invalidOption(fmt.Errorf("unknown line ending: %d", le))
+		}
+
+		f.lineEnding = le
+	})
+}
+
+// WithCommitModTimes reports the date of the last commit touching an entry as its modification time
+// (instead of the date of the commit the repository is read at).
+//
+// Commits are looked up lazily (when ModTime is called) and cached,
+// but it still costs an extra API request per entry.
+func WithCommitModTimes() Option {
+	return optionFunc(func(f *FS) {
+		f.commitModTimes = true
+	})
+}
+
+// WithEagerTree loads the entire tree of a repository with a single recursive request at first access
+// and serves directory listings and [FS.Stat] from memory.
+//
+// File content is still fetched per file.
+// Trees are kept for the lifetime of the filesystem, so changes to branches are not picked up.
+func WithEagerTree() Option {
+	return optionFunc(func(f *FS) {
+		f.eager = true
+	})
+}
+
+// WithArchiveBackend downloads the tarball of a repository at first access, extracts it into memory
+// and serves all reads (listings, [FS.Stat] and file content) from it.
+//
+// For workloads reading most of a repository this is considerably cheaper than per-file requests
+// and unaffected by rate limits, but the entire repository is held in memory.
+// Archives are kept for the lifetime of the filesystem, so changes to branches are not picked up.
+//
+// Tarballs honor the export-ignore and export-subst attributes in .gitattributes,
+// so this backend may serve a different tree (missing paths, substituted content) than the other backends.
+func WithArchiveBackend() Option {
+	return optionFunc(func(f *FS) {
+		f.archives = true
+	})
+}
+
+// WithGraphQLBackend lists directories using the GraphQL API, fetching entries (including their modes)
+// and the content of small text files with a single query per directory.
+//
+// Content fetched along with a directory is kept for the lifetime of the filesystem, so changes to branches are not picked up.
+// [Walk] fetches every level of the tree with a single (batched) query.
+// Repositories of an owner are listed with their push time, archived state and visibility in fewer requests.
+// Binary and large files are still fetched using the REST API.
+// The GraphQL API requires authentication (see [WithTokenSource]).
+func WithGraphQLBackend() Option {
+	return optionFunc(func(f *FS) {
+		f.graphql = true
+	})
+}
+
+// WithCache memoizes directory listings and file contents for ttl,
+// so that repeated reads of the same paths do not consume the API quota.
+//
+// Entries are cached per path and git reference (and shared with filesystems returned by [FS.Sub]).
+// Changes to branches are picked up once entries expire.
+// Entries read at a commit SHA (see [WithRef] and [WithRefAtTime]) never change, so they do not expire.
+// Tags are not treated as immutable: they can be moved.
+// File contents are also cached by their git blob SHA (for ttl): [FS.ReadFile] fetches identical files
+// (at other paths or git references) only once if their SHA is known from a cached listing or [WithEagerTree].
+// Use [WithMetadataCache] to cache listings for longer than file contents.
+func WithCache(ttl time.Duration) Option {
+	return optionFunc(func(f *FS) {
+		validateTTL(f, ttl)
+
+		f.cache = newResponseCache(ttl)
+	})
+}
+
+// WithSnapshot seeds the caches from a snapshot written by [FS.SaveSnapshot] (e.g. persisted between CI runs),
+// so that only git references that changed since are fetched again.
+//
+// File contents are only used if [WithCache] is enabled, and expire like other cached contents.
+// Snapshots written in an unknown format (e.g. by another version of this package) are ignored:
+// the filesystem starts with cold caches. Snapshots that cannot be decoded are reported as a configuration error.
+func WithSnapshot(r io.Reader) Option {
+	// The reader can only be consumed once, even if the option is applied multiple times.
+	read := sync.OnceValues(func() (*snapshot, error) {
+		return readSnapshot(r)
+	})
+
+	return optionFunc(func(f *FS) {
+		s, err := read()
+		if err != nil {
+			f.err = errors.Join(f.err, err)
+
+			return
+		}
+
+		f.snapshot = s
+	})
+}
+
+// WithMetadataCache memoizes directory listings for ttl, independently of [WithCache].
+//
+// [FS.ReadDir] and [FS.Stat] (which looks up entries in the listing of their parent directory) are served from the cache,
+// so walks filtering entries by name, size or type can be cached aggressively while file contents stay fresh.
+// Listings are shared between both: [FS.Stat] of an entry of a directory read by [FS.ReadDir] (e.g. during a walk)
+// does not send another request.
+//
+// Modification times of entries read at a branch (see [WithCommitModTimes]) are cached for ttl as well.
+func WithMetadataCache(ttl time.Duration) Option {
+	return optionFunc(func(f *FS) {
+		validateTTL(f, ttl)
+
+		f.metadata = newResponseCache(ttl)
+	})
+}
+
+// WithNegativeCache remembers paths that do not exist for ttl,
+// so that repeatedly probing for files (e.g. checking many repositories for a configuration file) is cheap.
+//
+// Files created within ttl after a failed lookup are reported as missing until the entry expires.
+// Paths missing at a commit SHA are remembered indefinitely.
+func WithNegativeCache(ttl time.Duration) Option {
+	return optionFunc(func(f *FS) {
+		validateTTL(f, ttl)
+
+		f.notFound = newResponseCache(ttl)
+	})
+}
+
+// WithConditionalRequests revalidates responses with conditional requests (using ETags),
+// so that reading resources that did not change does not consume the primary rate limit
+// (GitHub does not count 304 Not Modified responses against it).
+//
+// Responses (up to 1MB) are kept in memory for the lifetime of the filesystem.
+func WithConditionalRequests() Option {
+	return optionFunc(func(f *FS) {
+		f.etags = newETagCache()
+	})
+}
+
+// WithMaxConcurrency limits the number of requests in flight to n,
+// so that using the filesystem from many goroutines does not trigger secondary rate limits.
+//
+// Requests wait for a free slot (or until their context is canceled).
+// Slots are released once response headers are received, so open files do not hold them.
+func WithMaxConcurrency(n int) Option {
+	return optionFunc(func(f *FS) {
+		if n < 1 {
+			f.invalidOption(fmt.Errorf("max concurrency must be positive: %d", n))
+		}
+
+		f.limit = make(chan struct{}, max(n, 1))
+	})
+}
+
+// WithTimeout applies a deadline of d to each request sent by the filesystem
+// (including reading the response), so that a hung connection cannot stall an operation indefinitely.
+//
+// Requests exceeding the deadline fail with [context.DeadlineExceeded].
+// Time spent waiting for a request slot (see [WithMaxConcurrency]) does not count towards the deadline.
+// A zero duration disables the deadline.
+func WithTimeout(d time.Duration) Option {
+	return optionFunc(func(f *FS) {
+		if d < 0 {
+			f.invalidOption(fmt.Errorf("timeout must not be negative: %s", d))
+		}
+
+		f.timeout = d
+	})
+}
+
+// WithRetry retries GET requests failing with a server error (5xx) or a transient network error
+// according to policy, using exponential backoff with jitter between attempts.
+//
+// Each attempt counts towards the request budget (see [WithRequestBudget]) and gets its own deadline (see [WithTimeout]).
+// Zero fields of policy take their default values.
+func WithRetry(policy RetryPolicy) Option {
+	return optionFunc(func(f *FS) {
+		if policy.MaxAttempts < 0 || policy.MinBackoff < 0 || policy.MaxBackoff < 0 {
+			f.invalidOption(fmt.Errorf("retry policy must not have negative values: %+v", policy))
+		}
+
+		f.retry = &policy
+	})
+}
+
+// WithRequestBudget limits the number of requests the filesystem sends to n
+// (including requests of filesystems returned by [FS.Sub]).
+//
+// Requests beyond the budget fail with [ErrBudgetExceeded] without being sent,
+// so that batch jobs can bound their quota consumption.
+// See [FS.RequestsUsed] for the number of requests sent so far.
+func WithRequestBudget(n int) Option {
+	return optionFunc(func(f *FS) {
+		if n < 0 {
+			f.invalidOption(fmt.Errorf("request budget must not be negative: %d", n))
+		}
+
+		f.budget = &requestBudget{limit: int64(n)}
+	})
+}
+
+// WithPerPage configures the number of items requested per page by paginated listings
+// (repositories of an owner, code search results and commit comparisons).
+//
+// n is clamped to the range the API accepts (1 to 100). Defaults to 100.
+// Cursors (see [CursorDir]) are only valid for filesystems using the same page size (and listing backend, see [WithGraphQLBackend]).
+func WithPerPage(n int) Option {
+	return optionFunc(func(f *FS) {
+		if n < 1 || n > 100 {
+			f.invalidOption(fmt.Errorf("page size must be between 1 and 100: %d", n))
+		}
+
+		f.perPage = min(max(n, 1), 100)
+	})
+}
+
+// WithMemoryLimit caps the content the filesystem buffers in memory:
+// perFile bytes per file and total bytes across open files (including files of filesystems returned by [FS.Sub]).
+// Zero means no limit.
+//
+// Content buffered beyond the limits (to normalize line endings, see [WithLineEndingNormalization],
+// or in a custom media type, see [WithMediaType]) is spilled to temporary files that are removed when the file is closed.
+// File contents larger than perFile are not cached (see [WithCache] and [WithGraphQLBackend]).
+//
+// The limits do not apply to archives (see [WithArchiveBackend]), which are extracted in memory.
+func WithMemoryLimit(perFile int64, total int64) Option {
+	return optionFunc(func(f *FS) {
+		if perFile < 0 || total < 0 {
+			f.invalidOption(fmt.Errorf("memory limits must not be negative: %d, %d", perFile, total))
+		}
+
+		f.memory = &memoryLimit{perFile: perFile, total: total}
+	})
+}
+
+// WithMediaType configures the media type file content is requested in from the Contents API
+// (e.g. [MediaTypeHTML] to read Markdown files rendered by GitHub). Directories are not affected.
+//
+// Content in custom media types is buffered (see [WithMemoryLimit]) and not cached.
+// Its size differs from the size reported by [FS.Stat] and directory listings (which is the size of the file in git).
+// It does not apply to the archive, GraphQL and raw content backends.
+// An empty media type restores the default (the raw content of files).
+func WithMediaType(mediaType string) Option {
+	return optionFunc(func(f *FS) {
+		f.mediaType = mediaType
+	})
+}
+
+// WithVerify makes [NewFS] check that the configured owner, repository and ref exist and are readable
+// (see [FS.Validate]), so that errors like [fs.ErrNotExist] and [fs.ErrPermission] are reported before any content is read.
+//
+// It has no effect on [New].
+func WithVerify() Option {
+	return optionFunc(func(f *FS) {
+		f.verify = true
+	})
+}
+
+// WithGlobStrategy forces the strategy [FS.Glob] uses to find matches.
+//
+// Defaults to [GlobAuto], which never uses code search:
+// pass [GlobSearch] to opt into finding filename patterns with the code search API.
+func WithGlobStrategy(strategy GlobStrategy) Option {
+	return optionFunc(func(f *FS) {
+		if strategy < GlobAuto || strategy > GlobWalk {
+			f.invalidOption(fmt.Errorf("unknown glob strategy: %d", strategy))
+		}
+
+		f.globStrategy = strategy
+	})
+}
+
 // WithClient configures a [github.Client].
 func WithClient(c *github.Client) Option {
-	return optionFunc(func(f *fsys) {
-		f.client = c
+	return optionFunc(func(f *FS) {
+		if c == nil {
+			f.invalidOption(errors.New("client is nil"))
+		}
+
+		f.baseClient = c
+	})
+}
+
+// WithHTTPClient configures an [http.Client] to send requests with (e.g. one configured for oauth2, caching or proxies),
+// without constructing a [github.Client].
+func WithHTTPClient(c *http.Client) Option {
+	return optionFunc(func(f *FS) {
+		f.baseClient = github.NewClient(c)
+	})
+}
+
+// WithTransport configures an [http.RoundTripper] to send requests with (see [WithHTTPClient]).
+func WithTransport(rt http.RoundTripper) Option {
+	return WithHTTPClient(&http.Client{Transport: rt})
+}
+
+// WithProxy sends requests through an HTTP proxy (e.g. "http://proxy.example.com:3128")
+// instead of the proxy configured by the environment (HTTPS_PROXY and friends).
+//
+// Proxy and TLS options (see [WithRootCAs] and [WithClientCertificate]) configure a copy of the transport
+// of the client (see [WithClient] and [WithHTTPClient]), which must be an [http.Transport].
+// Invalid configurations are reported by every operation of the filesystem.
+func WithProxy(proxyURL string) Option {
+	return optionFunc(func(f *FS) {
+		u, err := url.Parse(proxyURL)
+		if err != nil {
+			f.err = errors.Join(f.err, fmt.Errorf("invalid proxy URL: %w", err))
+
+			return
+		}
+
+		f.transportConfig().proxy = http.ProxyURL(u)
+	})
+}
+
+// WithRootCAs trusts the PEM encoded certificates in addition to the system roots
+// (e.g. the CA of a GitHub Enterprise Server instance or a TLS intercepting corporate proxy).
+//
+// See [WithProxy] for the clients that can be configured.
+func WithRootCAs(pemCerts []byte) Option {
+	return optionFunc(func(f *FS) {
+		config := f.tlsConfig()
+
+		if config.RootCAs == nil {
+			pool, err := x509.SystemCertPool()
+			if err != nil {
+				pool = x509.NewCertPool()
+			}
+
+			config.RootCAs = pool
+		}
+
+		if !config.RootCAs.AppendCertsFromPEM(pemCerts) {
+			f.err = errors.Join(f.err, errors.New("no root CA certificates found"))
+		}
+	})
+}
+
+// WithClientCertificate presents a client certificate to servers requesting one (mutual TLS).
+//
+// See [WithProxy] for the clients that can be configured.
+func WithClientCertificate(cert tls.Certificate) Option {
+	return optionFunc(func(f *FS) {
+		config := f.tlsConfig()
+		config.Certificates = append(config.Certificates, cert)
+	})
+}
+
+// WithUserAgent identifies requests with a User-Agent header (e.g. "my-tool/1.2.3"),
+// so that operators of shared tokens can attribute traffic to the tools sending it.
+func WithUserAgent(ua string) Option {
+	return optionFunc(func(f *FS) {
+		f.userAgent = ua
+	})
+}
+
+// WithBaseURL configures the API (and upload) URL of a GitHub Enterprise Server instance
+// (e.g. "https://github.example.com/"), applied to the client configured by [WithClient] (or the default one).
+//
+// The "/api/v3/" suffix is added if it is missing. An empty upload URL defaults to the API URL.
+// Invalid URLs are reported by every operation of the filesystem.
+func WithBaseURL(api string, uploads string) Option {
+	return optionFunc(func(f *FS) {
+		f.baseURL = api
+		f.uploadURL = uploads
+	})
+}
+
+// WithClientFor sends requests targeting repositories of an owner (user or organization)
+// using the HTTP client of c (including its credentials, e.g. configured with [github.Client.WithAuthToken]),
+// so that a single filesystem can read from owners requiring separate tokens or GitHub App installations.
+//
+// Requests not targeting a specific owner (e.g. GraphQL queries) are sent using the default client.
+// The API URL of c is ignored: all owners must be served by the same host.
+func WithClientFor(owner string, c *github.Client) Option {
+	return optionFunc(func(f *FS) {
+		// Copy on write: the map is shared with the filesystem the options are applied on top of
+		f.owners = maps.Clone(f.owners)
+		if f.owners == nil {
+			f.owners = make(map[string]*github.Client)
+		}
+
+		f.owners[strings.ToLower(owner)] = c
+	})
+}
+
+// WithTokenSource configures an [oauth2.TokenSource] to authenticate requests with.
+//
+// Tokens are cached and refreshed automatically when they expire,
+// which makes it suitable for short-lived credentials (e.g. GitHub App installation tokens or OIDC token exchanges).
+func WithTokenSource(ts oauth2.TokenSource) Option {
+	return optionFunc(func(f *FS) {
+		f.tokenSource = ts
+	})
+}
+
+// WithToken configures a static token (e.g. a personal access token) to authenticate requests with.
+//
+// An empty token leaves requests unauthenticated, so a token can be read from the environment unconditionally.
+func WithToken(token string) Option {
+	if token == "" {
+		return optionFunc(func(*FS) {})
+	}
+
+	return WithTokenSource(oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}))
+}
+
+// WithTokenFunc configures a function resolving the token to authenticate each request with
+// (called with the context of the request), for credential rotation or tokens depending on the caller (e.g. the tenant).
+//
+// An empty token leaves the request unauthenticated. Requests fail if the function returns an error.
+// The function is called again when a request is retried after credentials expire (see [WithReauth]).
+// Caches (e.g. [WithCache]) are not partitioned by token: do not share them between tenants with different access.
+func WithTokenFunc(fn func(ctx context.Context) (string, error)) Option {
+	return optionFunc(func(f *FS) {
+		f.tokenFunc = fn
+	})
+}
+
+// WithEnv configures the filesystem from environment variables, for zero configuration in CI and on developer machines:
+//
+//   - GH_TOKEN or GITHUB_TOKEN: the token to authenticate requests with (see [WithToken])
+//   - GITHUB_API_URL: the API URL (see [WithBaseURL])
+//   - GH_HOST: the GitHub Enterprise Server host, unless GITHUB_API_URL is set
+//
+// Unset variables leave the defaults in place.
+func WithEnv() Option {
+	return optionFunc(func(f *FS) {
+		WithToken(cmp.Or(os.Getenv("GH_TOKEN"), os.Getenv("GITHUB_TOKEN"))).apply(f)
+
+		if api := os.Getenv("GITHUB_API_URL"); api != "" {
+			WithBaseURL(api, "").apply(f)
+		} else if host := os.Getenv("GH_HOST"); host != "" && host != "github.com" {
+			WithBaseURL("https://"+host+"/", "").apply(f)
+		}
+	})
+}
+
+// WithActionsDefaults configures the filesystem for a GitHub Actions workflow run:
+// it is rooted at the repository of the workflow (GITHUB_REPOSITORY) at the commit that triggered it (GITHUB_SHA),
+// authenticated with GITHUB_TOKEN and sends requests to GITHUB_API_URL.
+//
+// Unset variables leave the defaults in place.
+func WithActionsDefaults() Option {
+	return optionFunc(func(f *FS) {
+		WithToken(os.Getenv("GITHUB_TOKEN")).apply(f)
+
+		if api := os.Getenv("GITHUB_API_URL"); api != "" {
+			WithBaseURL(api, "").apply(f)
+		}
+
+		if owner, repo, ok := strings.Cut(os.Getenv("GITHUB_REPOSITORY"), "/"); ok {
+			WithRepository(owner, repo).apply(f)
+		}
+
+		if sha := os.Getenv("GITHUB_SHA"); sha != "" {
+			WithRef(sha).apply(f)
+		}
+	})
+}
+
+// WithGHCLIAuth authenticates requests with the credentials of the gh CLI
+// (for the host in GH_HOST, defaulting to github.com), so that developer tools work without configuration.
+//
+// The token is requested from the gh CLI if it is installed (including tokens stored in the system keyring),
+// otherwise it is read from its configuration directory.
+// Hosts other than github.com are configured as the base URL (see [WithBaseURL]).
+// Missing credentials are reported by every operation of the filesystem.
+func WithGHCLIAuth() Option {
+	return optionFunc(func(f *FS) {
+		host := os.Getenv("GH_HOST")
+		if host == "" {
+			host = "github.com"
+		}
+
+		ctx := f.ctx
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		token, err := ghCLIToken(ctx, host)
+		if err != nil {
+			f.err = errors.Join(f.err, fmt.Errorf("reading gh CLI credentials: %w", err))
+
+			return
+		}
+
+		f.tokenSource = oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
+
+		if host != "github.com" {
+			WithBaseURL("https://"+host+"/", "").apply(f)
+		}
+	})
+}
+
+// WithReauth configures a callback invoked when credentials expire during a session
+// (GitHub rejects a request with 401 after earlier requests succeeded).
+//
+// The callback should refresh the credentials used by the client (see [WithClient]).
+// Tokens of a configured [WithTokenSource] are refreshed automatically.
+// The rejected request is retried once after the callback returns.
+//
+// Requests that are still rejected fail with [ErrCredentialExpired].
+func WithReauth(fn func(ctx context.Context) error) Option {
+	return optionFunc(func(f *FS) {
+		f.reauth = fn
 	})
 }
 
 // WithContext configures a [context.Context].
+//
+// Cancelling it aborts in-progress downloads: reading files opened from the filesystem fails with the context error.
 func WithContext(ctx context.Context) Option {
-	return optionFunc(func(f *fsys) {
+	return optionFunc(func(f *FS) {
 		f.ctx = ctx
 	})
 }
 
 // WithContextFunc configures a function that creates a new context for each request.
 func WithContextFunc(fn func(context.Context) context.Context) Option {
-	return optionFunc(func(f *fsys) {
+	return optionFunc(func(f *FS) {
 		f.ctxFn = fn
 	})
 }
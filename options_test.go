@@ -0,0 +1,657 @@
+package githubfs
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v74/github"
+	"golang.org/x/oauth2"
+)
+
+type countingTokenSource struct {
+	n int
+}
+
+func (s *countingTokenSource) Token() (*oauth2.Token, error) {
+	s.n++
+
+	// Expire immediately so that every request needs a new token.
+	return &oauth2.Token{AccessToken: fmt.Sprintf("token-%d", s.n), Expiry: time.Now().Add(-time.Second)}, nil
+}
+
+func TestWithClientFor(t *testing.T) {
+	mux, opt := setup(t)
+
+	tokens := make(map[string]string)
+
+	mux.HandleFunc("GET /repos/{owner}/repo", func(w http.ResponseWriter, r *http.Request) {
+		tokens[r.PathValue("owner")] = r.Header.Get("Authorization")
+
+		w.Write([]byte(`{"name":"repo"}`))
+	})
+
+	fsys := New(opt, WithToken("default"), WithClientFor("Acme", github.NewClient(nil).WithAuthToken("acme")), WithClientFor("public", github.NewClient(nil)))
+
+	for _, name := range []string{"owner/repo", "acme/repo", "public/repo"} {
+		if _, err := fsys.Stat(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if tokens["owner"] != "Bearer default" || tokens["acme"] != "Bearer acme" || tokens["public"] != "" {
+		t.Errorf("unexpected authorization headers: %v", tokens)
+	}
+}
+
+func TestWithTokenSource(t *testing.T) {
+	mux, opt := setup(t)
+
+	var tokens []string
+
+	mux.HandleFunc("GET /repos/owner/repo", func(w http.ResponseWriter, r *http.Request) {
+		tokens = append(tokens, r.Header.Get("Authorization"))
+
+		w.Write([]byte(`{"name":"repo"}`))
+	})
+
+	fsys := New(opt, WithRepository("owner", "repo"), WithTokenSource(&countingTokenSource{}))
+
+	for range 2 {
+		if _, err := fsys.Stat("."); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(tokens) != 2 || tokens[0] != "Bearer token-1" || tokens[1] != "Bearer token-2" {
+		t.Errorf("expected expired tokens to be refreshed, got %v", tokens)
+	}
+}
+
+func TestWithToken(t *testing.T) {
+	mux, opt := setup(t)
+
+	var tokens []string
+
+	mux.HandleFunc("GET /repos/owner/repo", func(w http.ResponseWriter, r *http.Request) {
+		tokens = append(tokens, r.Header.Get("Authorization"))
+
+		w.Write([]byte(`{"name":"repo"}`))
+	})
+
+	for _, token := range []string{"token", ""} {
+		if _, err := New(opt, WithRepository("owner", "repo"), WithToken(token)).Stat("."); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(tokens) != 2 || tokens[0] != "Bearer token" || tokens[1] != "" {
+		t.Errorf("unexpected authorization headers: %q", tokens)
+	}
+}
+
+func TestWithTokenFunc(t *testing.T) {
+	mux, opt := setup(t)
+
+	var tokens []string
+
+	mux.HandleFunc("GET /repos/owner/repo", func(w http.ResponseWriter, r *http.Request) {
+		tokens = append(tokens, r.Header.Get("Authorization"))
+
+		w.Write([]byte(`{"name":"repo"}`))
+	})
+
+	type tenantKey struct{}
+
+	fsys := New(opt, WithRepository("owner", "repo"), WithTokenFunc(func(ctx context.Context) (string, error) {
+		tenant, _ := ctx.Value(tenantKey{}).(string)
+		if tenant == "unknown" {
+			return "", errors.New("unknown tenant")
+		}
+
+		return tenant, nil
+	}))
+
+	for _, tenant := range []string{"a", "b", ""} {
+		if _, err := fsys.StatContext(context.WithValue(context.Background(), tenantKey{}, tenant), "."); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if want := []string{"Bearer a", "Bearer b", ""}; !slices.Equal(tokens, want) {
+		t.Errorf("unexpected authorization headers: got %q, want %q", tokens, want)
+	}
+
+	if _, err := fsys.StatContext(context.WithValue(context.Background(), tenantKey{}, "unknown"), "."); err == nil {
+		t.Error("expected token errors to fail requests")
+	}
+}
+
+func TestWithEnv(t *testing.T) {
+	mux := http.NewServeMux()
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	var tokens []string
+
+	mux.HandleFunc("GET /api/v3/repos/owner/repo", func(w http.ResponseWriter, r *http.Request) {
+		tokens = append(tokens, r.Header.Get("Authorization"))
+
+		w.Write([]byte(`{"name":"repo"}`))
+	})
+
+	t.Setenv("GITHUB_TOKEN", "github-token")
+	t.Setenv("GH_TOKEN", "")
+	t.Setenv("GITHUB_API_URL", server.URL+"/api/v3")
+	t.Setenv("GH_HOST", "github.example.com")
+
+	if _, err := New(WithEnv(), WithRepository("owner", "repo")).Stat("."); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("GH_TOKEN", "gh-token")
+
+	if _, err := New(WithEnv(), WithRepository("owner", "repo")).Stat("."); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"Bearer github-token", "Bearer gh-token"}; !slices.Equal(tokens, want) {
+		t.Errorf("unexpected authorization headers: got %q, want %q", tokens, want)
+	}
+
+	t.Setenv("GITHUB_API_URL", "")
+
+	if got, want := New(WithEnv()).Client().BaseURL.String(), "https://github.example.com/api/v3/"; got != want {
+		t.Errorf("unexpected base URL: got %q, want %q", got, want)
+	}
+}
+
+func TestWithUserAgent(t *testing.T) {
+	mux, opt := setup(t)
+
+	var userAgents []string
+
+	mux.HandleFunc("GET /repos/owner/repo", func(w http.ResponseWriter, r *http.Request) {
+		userAgents = append(userAgents, r.UserAgent())
+
+		w.Write([]byte(`{"name":"repo"}`))
+	})
+
+	fsys := New(opt, WithRepository("owner", "repo"), WithUserAgent("my-tool/1.0"))
+
+	if _, err := fsys.Stat("."); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(userAgents) != 1 || userAgents[0] != "my-tool/1.0" {
+		t.Errorf("unexpected user agents: %q", userAgents)
+	}
+}
+
+func TestWithActionsDefaults(t *testing.T) {
+	mux := http.NewServeMux()
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	const sha = "0123456789abcdef0123456789abcdef01234567"
+
+	mux.HandleFunc("GET /api/v3/repos/owner/repo/contents/README.md", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer actions-token" {
+			t.Errorf("unexpected authorization header: %q", got)
+		}
+
+		if got := r.URL.Query().Get("ref"); got != sha {
+			t.Errorf("unexpected ref: %q", got)
+		}
+
+		fileHandler("content")(w, r)
+	})
+
+	t.Setenv("GITHUB_TOKEN", "actions-token")
+	t.Setenv("GITHUB_API_URL", server.URL+"/api/v3")
+	t.Setenv("GITHUB_REPOSITORY", "owner/repo")
+	t.Setenv("GITHUB_SHA", sha)
+
+	content, err := fs.ReadFile(New(WithActionsDefaults()), "README.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(content) != "content" {
+		t.Errorf("unexpected content: %q", content)
+	}
+}
+
+func TestWithReauth(t *testing.T) {
+	mux, opt := setup(t)
+
+	var valid bool
+
+	mux.HandleFunc("GET /repos/owner/repo", func(w http.ResponseWriter, r *http.Request) {
+		if !valid {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"message":"Bad credentials"}`))
+
+			return
+		}
+
+		w.Write([]byte(`{"name":"repo"}`))
+	})
+
+	t.Run("InvalidCredentials", func(t *testing.T) {
+		valid = false
+
+		_, err := New(opt, WithRepository("owner", "repo")).Stat(".")
+		if !errors.Is(err, fs.ErrPermission) || errors.Is(err, ErrCredentialExpired) {
+			t.Errorf("expected credentials that never worked to be reported as invalid, got %v", err)
+		}
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		valid = true
+
+		fsys := New(opt, WithRepository("owner", "repo"))
+
+		if _, err := fsys.Stat("."); err != nil {
+			t.Fatal(err)
+		}
+
+		valid = false
+
+		_, err := fsys.Stat(".")
+		if !errors.Is(err, ErrCredentialExpired) || !errors.Is(err, fs.ErrPermission) {
+			t.Errorf("expected expired credentials, got %v", err)
+		}
+	})
+
+	t.Run("Reauth", func(t *testing.T) {
+		valid = true
+
+		var reauths int
+
+		fsys := New(opt, WithRepository("owner", "repo"), WithReauth(func(ctx context.Context) error {
+			reauths++
+			valid = true
+
+			return nil
+		}))
+
+		if _, err := fsys.Stat("."); err != nil {
+			t.Fatal(err)
+		}
+
+		valid = false
+
+		if _, err := fsys.Stat("."); err != nil {
+			t.Fatalf("expected request to be retried after reauthenticating: %v", err)
+		}
+
+		if reauths != 1 {
+			t.Errorf("expected a single reauthentication, got %d", reauths)
+		}
+	})
+
+	t.Run("PerCredential", func(t *testing.T) {
+		type tenantKey struct{}
+
+		mux.HandleFunc("GET /repos/{owner}/repo", func(w http.ResponseWriter, r *http.Request) {
+			if r.PathValue("owner") != "a" || r.Header.Get("Authorization") != "Bearer good" {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"message":"Bad credentials"}`))
+
+				return
+			}
+
+			w.Write([]byte(`{"name":"repo"}`))
+		})
+
+		fsys := New(opt, WithTokenFunc(func(ctx context.Context) (string, error) {
+			if tenant, ok := ctx.Value(tenantKey{}).(string); ok {
+				return tenant, nil
+			}
+
+			return "good", nil
+		}))
+
+		if _, err := fsys.Stat("a/repo"); err != nil {
+			t.Fatal(err)
+		}
+
+		// Owner a succeeding does not make the credentials used for owner b expired.
+		_, err := fsys.Stat("b/repo")
+		if !errors.Is(err, fs.ErrPermission) || errors.Is(err, ErrCredentialExpired) {
+			t.Errorf("expected credentials for another owner to be reported as invalid, got %v", err)
+		}
+
+		// Neither does another token for the same owner.
+		_, err = fsys.StatContext(context.WithValue(t.Context(), tenantKey{}, "bad"), "a/repo")
+		if !errors.Is(err, fs.ErrPermission) || errors.Is(err, ErrCredentialExpired) {
+			t.Errorf("expected another token to be reported as invalid, got %v", err)
+		}
+	})
+}
+
+func TestWithMaxConcurrency(t *testing.T) {
+	mux, opt := setup(t)
+
+	var (
+		mu       sync.Mutex
+		inFlight int
+		peak     int
+	)
+
+	mux.HandleFunc("GET /repos/owner/repo/contents/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		peak = max(peak, inFlight)
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+
+		fileHandler("content")(w, r)
+	})
+
+	fsys := New(opt, WithRepository("owner", "repo"), WithMaxConcurrency(2))
+
+	var wg sync.WaitGroup
+
+	for i := range 8 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if _, err := fs.ReadFile(fsys, fmt.Sprintf("file-%d.txt", i)); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if peak > 2 {
+		t.Errorf("expected at most 2 concurrent requests, got %d", peak)
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	mux, opt := setup(t)
+
+	mux.HandleFunc("GET /repos/owner/repo/contents/hang", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	})
+	mux.HandleFunc("GET /repos/owner/repo/contents/file", fileHandler("content"))
+
+	fsys := New(opt, WithRepository("owner", "repo"), WithTimeout(50*time.Millisecond))
+
+	if _, err := fsys.Open("hang"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+
+	content, err := fs.ReadFile(fsys, "file")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(content) != "content" {
+		t.Errorf("unexpected content: %q", content)
+	}
+}
+
+func TestWithRetry(t *testing.T) {
+	mux, opt := setup(t)
+
+	var attempts int
+
+	mux.HandleFunc("GET /repos/owner/repo/contents/flaky", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+
+		if attempts < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+
+			return
+		}
+
+		fileHandler("content")(w, r)
+	})
+	mux.HandleFunc("GET /repos/owner/repo/contents/broken", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	mux.HandleFunc("GET /repos/owner/repo/contents/missing", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+
+		http.NotFound(w, r)
+	})
+
+	fsys := New(opt, WithRepository("owner", "repo"), WithRetry(RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond}))
+
+	content, err := fs.ReadFile(fsys, "flaky")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(content) != "content" || attempts != 3 {
+		t.Errorf("unexpected result: %q after %d attempts", content, attempts)
+	}
+
+	attempts = 0
+
+	if _, err := fsys.Open("broken"); err == nil || attempts != 3 {
+		t.Errorf("expected an error after 3 attempts, got %v after %d attempts", err, attempts)
+	}
+
+	attempts = 0
+
+	if _, err := fsys.Open("missing"); !errors.Is(err, fs.ErrNotExist) || attempts != 1 {
+		t.Errorf("expected client errors not to be retried, got %v after %d attempts", err, attempts)
+	}
+}
+
+func TestWithRequestBudget(t *testing.T) {
+	mux, opt := setup(t)
+
+	var requests int
+
+	mux.HandleFunc("GET /repos/owner/repo/contents/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		fileHandler("content")(w, r)
+	})
+
+	fsys := New(opt, WithRepository("owner", "repo"), WithRequestBudget(2))
+
+	for range 2 {
+		if _, err := fs.ReadFile(fsys, "README.md"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, err := fs.ReadFile(fsys, "README.md")
+	if !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("expected %v, got %v", ErrBudgetExceeded, err)
+	}
+
+	if requests != 2 || fsys.RequestsUsed() != 2 {
+		t.Errorf("expected requests beyond the budget to not be sent: %d sent, %d used", requests, fsys.RequestsUsed())
+	}
+}
+
+func TestWithPerPage(t *testing.T) {
+	mux, opt := setup(t)
+
+	mux.HandleFunc("GET /users/owner/repos", func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.URL.Query().Get("per_page"), "30"; got != want {
+			t.Errorf("unexpected page size: got %q, want %q", got, want)
+		}
+
+		w.Write([]byte(`[{"name":"repo"}]`))
+	})
+
+	if _, err := fs.ReadDir(New(opt, WithOwner("owner"), WithPerPage(30)), "."); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWithBaseURL(t *testing.T) {
+	mux := http.NewServeMux()
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("GET /api/v3/repos/owner/repo/contents/README.md", fileHandler("content"))
+
+	fsys := New(WithBaseURL(server.URL, ""), WithRepository("owner", "repo"))
+
+	content, err := fs.ReadFile(fsys, "README.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(content) != "content" {
+		t.Errorf("unexpected content: %q", content)
+	}
+
+	if got, want := fsys.Client().UploadURL.String(), server.URL+"/api/uploads/"; got != want {
+		t.Errorf("unexpected upload URL: got %q, want %q", got, want)
+	}
+
+	// The base URL is applied to a custom client
+	fsys = New(WithClient(github.NewClient(nil)), WithBaseURL(server.URL, ""), WithRepository("owner", "repo"))
+
+	if _, err := fs.ReadFile(fsys, "README.md"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := fs.ReadFile(New(WithBaseURL("://invalid", "")), "owner/repo/README.md"); err == nil {
+		t.Error("expected an invalid base URL to be reported")
+	}
+}
+
+type countingTransport struct {
+	requests int
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests++
+
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestWithTransport(t *testing.T) {
+	mux := http.NewServeMux()
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("GET /api/v3/repos/owner/repo/contents/README.md", fileHandler("content"))
+
+	transport := &countingTransport{}
+
+	fsys := New(WithTransport(transport), WithBaseURL(server.URL, ""), WithRepository("owner", "repo"))
+
+	if _, err := fs.ReadFile(fsys, "README.md"); err != nil {
+		t.Fatal(err)
+	}
+
+	if transport.requests != 1 {
+		t.Errorf("expected requests to be sent with the configured transport, got %d requests", transport.requests)
+	}
+
+	client := &http.Client{Transport: transport}
+
+	if _, err := fs.ReadFile(New(WithHTTPClient(client), WithBaseURL(server.URL, ""), WithRepository("owner", "repo")), "README.md"); err != nil {
+		t.Fatal(err)
+	}
+
+	if transport.requests != 2 {
+		t.Errorf("expected requests to be sent with the configured client, got %d requests", transport.requests)
+	}
+
+	if client.Transport != transport {
+		t.Error("expected the configured client not to be modified")
+	}
+}
+
+func TestWithProxy(t *testing.T) {
+	var hosts []string
+
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hosts = append(hosts, r.Host)
+
+		fileHandler("content")(w, r)
+	}))
+	t.Cleanup(proxy.Close)
+
+	fsys := New(WithBaseURL("http://github.example.com/", ""), WithProxy(proxy.URL), WithRepository("owner", "repo"))
+
+	if _, err := fs.ReadFile(fsys, "README.md"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(hosts) != 1 || hosts[0] != "github.example.com" {
+		t.Errorf("expected requests to be sent through the proxy, got %v", hosts)
+	}
+
+	fsys = New(WithTransport(&countingTransport{}), WithProxy(proxy.URL))
+
+	if _, err := fsys.Stat("owner/repo"); err == nil {
+		t.Error("expected custom transports to be rejected")
+	}
+}
+
+func TestWithRootCAs(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v3/repos/owner/repo/contents/README.md", fileHandler("content"))
+
+	server := httptest.NewUnstartedServer(mux)
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	cert := server.TLS.Certificates[0]
+
+	fsys := New(WithBaseURL(server.URL, ""), WithRootCAs(ca), WithClientCertificate(cert), WithRepository("owner", "repo"))
+
+	if _, err := fs.ReadFile(fsys, "README.md"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The server requires a client certificate
+	fsys = New(WithBaseURL(server.URL, ""), WithRootCAs(ca), WithRepository("owner", "repo"))
+
+	if _, err := fs.ReadFile(fsys, "README.md"); err == nil {
+		t.Error("expected requests without a client certificate to fail")
+	}
+
+	// The server certificate is not trusted
+	fsys = New(WithBaseURL(server.URL, ""), WithClientCertificate(cert), WithRepository("owner", "repo"))
+
+	if _, err := fs.ReadFile(fsys, "README.md"); err == nil {
+		t.Error("expected requests to untrusted servers to fail")
+	}
+
+	if _, err := New(WithRootCAs([]byte("invalid"))).Stat("owner/repo"); err == nil {
+		t.Error("expected invalid certificates to be reported")
+	}
+}
@@ -0,0 +1,51 @@
+package githubfs
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// prefetchConcurrency is the maximum number of paths [FS.Prefetch] fetches at the same time.
+const prefetchConcurrency = 8
+
+// Prefetch fetches files and directories concurrently, so that servers can warm hot paths at startup.
+//
+// Responses are kept in the configured caches (see [WithCache] and [WithMetadataCache]):
+// without a cache Prefetch only reports paths that cannot be read.
+// Errors of all paths are joined.
+func (f *FS) Prefetch(ctx context.Context, paths ...string) error {
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+
+	sem := make(chan struct{}, prefetchConcurrency)
+
+	for _, name := range paths {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			file, err := f.open(ctx, name)
+			if err == nil {
+				err = file.Close()
+			}
+
+			if err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
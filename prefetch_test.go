@@ -0,0 +1,50 @@
+package githubfs
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPrefetch(t *testing.T) {
+	mux, opt := setup(t)
+
+	var requests atomic.Int32
+
+	mux.HandleFunc("GET /repos/owner/repo/contents/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		switch r.PathValue("path") {
+		case "docs":
+			w.Write([]byte(`[{"type":"file","name":"index.md","path":"docs/index.md","size":7}]`))
+		case "missing.txt":
+			http.NotFound(w, r)
+		default:
+			fileHandler("content")(w, r)
+		}
+	})
+
+	fsys := New(opt, WithRepository("owner", "repo"), WithCache(time.Hour))
+
+	err := fsys.Prefetch(t.Context(), "docs", "README.md", "missing.txt")
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected missing path to be reported, got %v", err)
+	}
+
+	if _, err := fs.ReadDir(fsys, "docs"); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := fsys.Open("README.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	if got := requests.Load(); got != 3 {
+		t.Errorf("expected prefetched paths to be served from the cache, got %d requests", got)
+	}
+}
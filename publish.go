@@ -0,0 +1,228 @@
+package githubfs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+)
+
+// BlobStore is a shared, content-addressed store snapshots are published to (see [FS.Publish]).
+type BlobStore interface {
+	// Put stores content under key.
+	//
+	// Keys are content-addressed, so stores may skip writing blobs they already hold.
+	Put(ctx context.Context, key string, content []byte) error
+
+	// Get returns the content stored under key.
+	//
+	// Returns an error wrapping [fs.ErrNotExist] if the key does not exist.
+	Get(ctx context.Context, key string) ([]byte, error)
+}
+
+// Manifest describes a snapshot of a subtree published to a [BlobStore].
+type Manifest struct {
+	Owner string `json:"owner"`
+	Repo  string `json:"repo"`
+
+	// Tree is the SHA of the published git tree.
+	Tree string `json:"tree"`
+
+	Entries []ManifestEntry `json:"entries"`
+}
+
+// ManifestEntry is a file or directory in a [Manifest].
+type ManifestEntry struct {
+	// Path is relative to the published root.
+	Path string      `json:"path"`
+	Mode fs.FileMode `json:"mode"`
+	Size int64       `json:"size,omitempty"`
+
+	// Blob is the key of the file content in the store (the SHA of the git blob).
+	Blob string `json:"blob,omitempty"`
+}
+
+// manifestKey returns the key a manifest is stored under.
+func manifestKey(tree string) string {
+	return "manifests/" + tree
+}
+
+// Publish pushes the subtree at root (blobs and a manifest) into a shared blob store,
+// so that other processes can read it using [NewFromStore] without fetching it from GitHub again.
+//
+// Blobs are stored by their git SHA, the manifest by the SHA of the tree.
+// Files are published as they are stored in git (options transforming content, like [WithLFS], are not applied).
+func (f *FS) Publish(ctx context.Context, dst BlobStore, root string) (*Manifest, error) {
+	r, err := f.resolve("publish", root)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.repo == "" {
+		return nil, &fs.PathError{Op: "publish", Path: root, Err: fmt.Errorf("root must be inside a repository: %w", fs.ErrInvalid)}
+	}
+
+	revision, err := f.resolveRevision(ctx, r.owner, r.repo)
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := f.getCompleteTree(ctx, "publish", r, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &Manifest{
+		Owner: r.owner,
+		Repo:  r.repo,
+		Tree:  tree.GetSHA(),
+	}
+
+	for _, entry := range tree.Entries {
+		switch entry.GetType() {
+		case "tree":
+			manifest.Entries = append(manifest.Entries, ManifestEntry{
+				Path: entry.GetPath(),
+				Mode: fs.ModeDir | 0o755,
+			})
+
+		case "blob":
+			content, _, err := f.client.Git.GetBlobRaw(f.ctxFn(ctx), r.owner, r.repo, entry.GetSHA())
+			if err := handleErr(err, "publish", ref{owner: r.owner, repo: r.repo, path: path.Join(r.path, entry.GetPath())}.string()); err != nil {
+				return nil, err
+			}
+
+			if err := dst.Put(ctx, entry.GetSHA(), content); err != nil {
+				return nil, err
+			}
+
+			mode := fs.FileMode(0o644)
+			if entry.GetMode() == "100755" {
+				mode = 0o755
+			}
+
+			manifest.Entries = append(manifest.Entries, ManifestEntry{
+				Path: entry.GetPath(),
+				Mode: mode,
+				Size: int64(len(content)),
+				Blob: entry.GetSHA(),
+			})
+		}
+	}
+
+	b, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := dst.Put(ctx, manifestKey(manifest.Tree), b); err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// LoadManifest reads a manifest published by [FS.Publish] from a store.
+func LoadManifest(ctx context.Context, store BlobStore, tree string) (*Manifest, error) {
+	b, err := store.Get(ctx, manifestKey(tree))
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(b, &manifest); err != nil {
+		return nil, err
+	}
+
+	return &manifest, nil
+}
+
+// NewFromStore returns a read-only filesystem serving a snapshot published by [FS.Publish].
+//
+// File content is read from the store when files are opened.
+func NewFromStore(store BlobStore, manifest *Manifest) fs.FS {
+	fsys := &storeFS{
+		store: store,
+		files: make(map[string]ManifestEntry),
+		dirs:  map[string][]ManifestEntry{".": nil},
+	}
+
+	for _, entry := range manifest.Entries {
+		dir := path.Dir(entry.Path)
+
+		fsys.dirs[dir] = append(fsys.dirs[dir], entry)
+
+		if entry.Mode.IsDir() {
+			if _, ok := fsys.dirs[entry.Path]; !ok {
+				fsys.dirs[entry.Path] = nil
+			}
+		} else {
+			fsys.files[entry.Path] = entry
+		}
+	}
+
+	return fsys
+}
+
+// storeFS serves a published snapshot from a [BlobStore].
+type storeFS struct {
+	store BlobStore
+	files map[string]ManifestEntry
+	dirs  map[string][]ManifestEntry
+}
+
+func (s *storeFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	if entries, ok := s.dirs[name]; ok {
+		modes := manifestModes(entries)
+
+		dirEntries := make([]*dirEntry, 0, len(entries))
+		for _, entry := range entries {
+			dirEntries = append(dirEntries, &dirEntry{
+				name:  path.Base(entry.Path),
+				isDir: entry.Mode.IsDir(),
+				size:  entry.Size,
+				modes: modes,
+			})
+		}
+
+		sortEntries(dirEntries)
+
+		return &dir{name: path.Base(name), entries: dirEntries}, nil
+	}
+
+	entry, ok := s.files[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	content, err := s.store.Get(context.Background(), entry.Blob)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+
+	return &file{
+		name:    path.Base(name),
+		size:    int64(len(content)),
+		modes:   manifestModes([]ManifestEntry{entry}),
+		content: nopSeekCloser{bytes.NewReader(content)},
+		sha:     entry.Blob,
+	}, nil
+}
+
+// manifestModes returns a mode resolver for manifest entries of a directory.
+func manifestModes(entries []ManifestEntry) *treeModes {
+	modes := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		if entry.Mode&0o111 != 0 && !entry.Mode.IsDir() {
+			modes[path.Base(entry.Path)] = "100755"
+		}
+	}
+
+	return staticModes(modes)
+}
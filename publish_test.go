@@ -0,0 +1,101 @@
+package githubfs
+
+import (
+	"context"
+	"io/fs"
+	"net/http"
+	"sync"
+	"testing"
+	"testing/fstest"
+)
+
+type memStore struct {
+	mu    sync.Mutex
+	blobs map[string][]byte
+}
+
+func (s *memStore) Put(_ context.Context, key string, content []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.blobs[key] = content
+
+	return nil
+}
+
+func (s *memStore) Get(_ context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	content, ok := s.blobs[key]
+	if !ok {
+		return nil, fs.ErrNotExist
+	}
+
+	return content, nil
+}
+
+func TestPublish(t *testing.T) {
+	mux, opt := setup(t)
+
+	blobs := map[string]string{
+		"a1": "# docs",
+		"b2": "#!/bin/sh",
+	}
+
+	mux.HandleFunc("GET /repos/owner/repo/git/trees/{sha...}", func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.PathValue("sha"), "HEAD:docs"; got != want {
+			t.Errorf("unexpected tree-ish: got %q, want %q", got, want)
+		}
+
+		w.Write([]byte(`{"sha":"t1","tree":[
+			{"path":"README.md","mode":"100644","type":"blob","sha":"a1","size":6},
+			{"path":"scripts","mode":"040000","type":"tree","sha":"t2"},
+			{"path":"scripts/run.sh","mode":"100755","type":"blob","sha":"b2","size":9}
+		]}`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/git/blobs/{sha}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(blobs[r.PathValue("sha")]))
+	})
+
+	fsys := New(opt, WithRepository("owner", "repo"))
+	store := &memStore{blobs: make(map[string][]byte)}
+
+	manifest, err := fsys.Publish(t.Context(), store, "docs")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if manifest.Tree != "t1" {
+		t.Errorf("unexpected tree: %q", manifest.Tree)
+	}
+
+	loaded, err := LoadManifest(t.Context(), store, "t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	published := NewFromStore(store, loaded)
+
+	if err := fstest.TestFS(published, "README.md", "scripts/run.sh"); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := fs.ReadFile(published, "scripts/run.sh")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(content) != "#!/bin/sh" {
+		t.Errorf("unexpected content: %q", content)
+	}
+
+	info, err := fs.Stat(published, "scripts/run.sh")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := info.Mode(), fs.FileMode(0o755); got != want {
+		t.Errorf("unexpected mode: got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,349 @@
+package githubfs
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/google/go-github/v74/github"
+)
+
+const mediaTypeRaw = "application/vnd.github.raw"
+
+// openRaw requests the content of a file using the raw media type and returns the response body as a stream.
+//
+// Unlike the default JSON representation, the raw media type does not require decoding the base64-encoded content
+// into memory and works for files up to 100MB.
+func (f *FS) openRaw(ctx context.Context, r ref, revision string, header http.Header) (*http.Response, error) {
+	u := fmt.Sprintf("repos/%s/%s/contents/%s", r.owner, r.repo, (&url.URL{Path: strings.TrimSuffix(r.path, "/")}).String())
+	if revision != "" {
+		u += "?ref=" + url.QueryEscape(revision)
+	}
+
+	req, err := f.client.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", mediaTypeRaw)
+
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	resp, err := f.client.BareDo(f.ctxFn(ctx), req)
+	if err := handleErr(err, "open", r.string()); err != nil {
+		return nil, err
+	}
+
+	return resp.Response, nil
+}
+
+// Media types of file content (see [WithMediaType]).
+const (
+	// MediaTypeHTML requests file content rendered as HTML (e.g. for Markdown files).
+	MediaTypeHTML = "application/vnd.github.html+json"
+
+	// MediaTypeObject requests the JSON representation of files (including metadata and base64-encoded content).
+	MediaTypeObject = "application/vnd.github.object+json"
+)
+
+// openMediaType returns a file serving the content of fileContent in the configured media type.
+func (f *FS) openMediaType(ctx context.Context, r ref, revision string, fileContent *github.RepositoryContent) (*file, error) {
+	resp, err := f.openRaw(ctx, r, revision, http.Header{"Accept": {f.mediaType}})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	content, err := f.spoolContent(resp.Body)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: r.string(), Err: err}
+	}
+
+	return &file{
+		name:    fileContent.GetName(),
+		size:    content.size,
+		modes:   f.treeModes(ctx, r.parent(), revision),
+		modTime: f.modTime(ctx, r, revision),
+		sys:     fileContent,
+		content: content,
+
+		sha:         fileContent.GetSHA(),
+		htmlURL:     fileContent.GetHTMLURL(),
+		downloadURL: fileContent.GetDownloadURL(),
+		revision:    revision,
+	}, nil
+}
+
+// newFile returns a file serving the content of fileContent.
+func (f *FS) newFile(ctx context.Context, r ref, revision string, fileContent *github.RepositoryContent) (*file, error) {
+	file := &file{
+		name:    fileContent.GetName(),
+		size:    int64(fileContent.GetSize()),
+		modes:   f.treeModes(ctx, r.parent(), revision),
+		modTime: f.modTime(ctx, r, revision),
+		sys:     fileContent,
+
+		sha:         fileContent.GetSHA(),
+		htmlURL:     fileContent.GetHTMLURL(),
+		downloadURL: fileContent.GetDownloadURL(),
+		revision:    revision,
+	}
+
+	// The Contents API omits the content of files between 1MB and 100MB,
+	// so large files are streamed using the raw media type instead of being held in memory.
+	if fileContent.GetEncoding() == "none" {
+		file.reopen = func() (io.ReadCloser, error) {
+			resp, err := f.openRaw(ctx, r, revision, nil)
+			if err != nil {
+				return nil, err
+			}
+
+			return resp.Body, nil
+		}
+		file.openRange = func(off int64, n int64) (io.ReadCloser, error) {
+			resp, err := f.openRaw(ctx, r, revision, rangeHeader(off, n))
+			if err != nil {
+				return nil, err
+			}
+
+			return rangeBody(resp, off)
+		}
+
+		return file, file.open()
+	}
+
+	// Smaller files are inlined in the response, so their encoded content is already in memory
+	// (and stays there, as fileContent is exposed by Sys).
+	// Decoding it on read only avoids holding a decoded copy as well.
+	// LFS pointers are small, so they are still decoded upfront when LFS is enabled.
+	if fileContent.GetEncoding() == "base64" && fileContent.Content != nil && !(f.lfs && file.size < 1024) {
+		encoded := *fileContent.Content
+
+		file.reopen = func() (io.ReadCloser, error) {
+			return io.NopCloser(base64.NewDecoder(base64.StdEncoding, strings.NewReader(encoded))), nil
+		}
+
+		return file, file.open()
+	}
+
+	content, err := fileContent.GetContent()
+	if err != nil {
+		return nil, err
+	}
+
+	if f.lfs {
+		if pointer, ok := parseLFSPointer(content); ok {
+			file.size = pointer.size
+			file.reopen = func() (io.ReadCloser, error) {
+				return f.openLFSObject(ctx, r, pointer)
+			}
+			file.openRange = func(off int64, n int64) (io.ReadCloser, error) {
+				return f.openLFSRange(ctx, r, pointer, off, n)
+			}
+
+			return file, file.open()
+		}
+	}
+
+	file.content = nopSeekCloser{strings.NewReader(content)}
+
+	return file, nil
+}
+
+// defaultRawURL is the host serving raw file content of public repositories.
+const defaultRawURL = "https://raw.githubusercontent.com/"
+
+// rawBaseURL returns the URL raw file content is served at for an API URL:
+// GitHub Enterprise Server serves it at "/raw/" on the same host as the API.
+func rawBaseURL(api *url.URL) *url.URL {
+	if api == nil || api.Host == "api.github.com" {
+		u, _ := url.Parse(defaultRawURL)
+
+		return u
+	}
+
+	return &url.URL{Scheme: api.Scheme, Host: api.Host, Path: "/raw/"}
+}
+
+// openRawBackend fetches a file from the raw content host, bypassing the API quota.
+//
+// Returns false if the host does not serve a file at the path (eg. because it is a directory).
+func (f *FS) openRawBackend(ctx context.Context, r ref, revision string) (*file, bool, error) {
+	resp, err := f.getRawBackend(ctx, r, revision, nil)
+	if err != nil || resp == nil {
+		return nil, false, err
+	}
+
+	file := &file{
+		name:    path.Base(r.path),
+		size:    resp.ContentLength,
+		modes:   f.treeModes(ctx, r.parent(), revision),
+		modTime: f.modTime(ctx, r, revision),
+		content: resp.Body,
+
+		downloadURL: resp.Request.URL.String(),
+		revision:    revision,
+
+		reopen: func() (io.ReadCloser, error) {
+			resp, err := f.getRawBackend(ctx, r, revision, nil)
+			if err != nil {
+				return nil, err
+			}
+
+			if resp == nil {
+				return nil, &fs.PathError{Op: "open", Path: r.string(), Err: fs.ErrNotExist}
+			}
+
+			return resp.Body, nil
+		},
+		openRange: func(off int64, n int64) (io.ReadCloser, error) {
+			resp, err := f.getRawBackend(ctx, r, revision, rangeHeader(off, n))
+			if err != nil {
+				return nil, err
+			}
+
+			if resp == nil {
+				return nil, &fs.PathError{Op: "open", Path: r.string(), Err: fs.ErrNotExist}
+			}
+
+			return rangeBody(resp, off)
+		},
+	}
+
+	// The size of the content is not known upfront if the response is chunked, so it is buffered to find it.
+	// LFS pointers are served as is: small files are buffered to find pointers.
+	if file.size < 0 || f.lfs && file.size < 1024 {
+		content, err := f.spoolContent(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, false, err
+		}
+
+		file.content = content
+		file.size = content.size
+
+		if !f.lfs {
+			return file, true, nil
+		}
+
+		b := make([]byte, min(content.size, 1024))
+		if _, err := content.ReadAt(b, 0); err != nil && err != io.EOF {
+			content.Close()
+
+			return nil, false, err
+		}
+
+		if pointer, ok := parseLFSPointer(string(b)); ok && content.size < 1024 {
+			content.Close()
+
+			file.size = pointer.size
+			file.reopen = func() (io.ReadCloser, error) {
+				return f.openLFSObject(ctx, r, pointer)
+			}
+			file.openRange = func(off int64, n int64) (io.ReadCloser, error) {
+				return f.openLFSRange(ctx, r, pointer, off, n)
+			}
+
+			if err := file.open(); err != nil {
+				return nil, false, err
+			}
+		}
+	}
+
+	return file, true, nil
+}
+
+// getRawBackend requests a file from the raw content host.
+//
+// Returns a nil response if the file does not exist.
+func (f *FS) getRawBackend(ctx context.Context, r ref, revision string, header http.Header) (*http.Response, error) {
+	if revision == "" {
+		revision = "HEAD"
+	}
+
+	u := f.rawURL.JoinPath(r.owner, r.repo, revision, r.path)
+
+	req, err := http.NewRequestWithContext(f.ctxFn(ctx), http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Transparently decompressed responses have no length, which is needed for the size of the file.
+	req.Header.Set("Accept-Encoding", "identity")
+
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	resp, err := f.client.Client().Do(req)
+	if err := handleErr(err, "open", r.string()); err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusPartialContent:
+		return resp, nil
+
+	case http.StatusNotFound:
+		resp.Body.Close()
+
+		return nil, nil
+
+	default:
+		resp.Body.Close()
+
+		return nil, &fs.PathError{Op: "open", Path: r.string(), Err: fmt.Errorf("unexpected status: %s", resp.Status)}
+	}
+}
+
+// rangeHeader returns the headers of an HTTP Range request for n bytes starting at off.
+func rangeHeader(off int64, n int64) http.Header {
+	return http.Header{"Range": {fmt.Sprintf("bytes=%d-%d", off, off+n-1)}}
+}
+
+// rangeBody returns the content of a response to a Range request starting at off.
+//
+// Servers may ignore the Range header and respond with the entire content.
+func rangeBody(resp *http.Response, off int64) (io.ReadCloser, error) {
+	if resp.StatusCode == http.StatusPartialContent {
+		return resp.Body, nil
+	}
+
+	if _, err := io.CopyN(io.Discard, resp.Body, off); err != nil {
+		resp.Body.Close()
+
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
+
+// isDirListing reports whether a raw media type response is a directory listing.
+//
+// The raw media type only applies to files: directories are still returned as a JSON list of entries.
+func isDirListing(resp *http.Response, content []byte) bool {
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "application/json" {
+		return false
+	}
+
+	var entries []*github.RepositoryContent
+	if err := json.Unmarshal(content, &entries); err != nil || len(entries) == 0 {
+		return false
+	}
+
+	return !slices.ContainsFunc(entries, func(e *github.RepositoryContent) bool {
+		return e.Type == nil || e.Path == nil
+	})
+}
@@ -0,0 +1,203 @@
+package githubfs
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRawBackend(t *testing.T) {
+	mux, opt := setup(t)
+
+	mux.HandleFunc("GET /raw/owner/repo/main/docs/README.md", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello"))
+	})
+	mux.HandleFunc("GET /raw/owner/repo/main/docs", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+	mux.HandleFunc("GET /repos/owner/repo/contents/docs", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"type":"file","name":"README.md","size":5}]`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/contents/docs/README.md", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("file content should not be read from the API")
+	})
+
+	// The test server is not api.github.com, so raw content is read from the Enterprise Server path
+	fsys := New(opt, WithRepository("owner", "repo"), WithRef("main"), WithRawBackend())
+
+	content, err := fs.ReadFile(fsys, "docs/README.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(content) != "hello" {
+		t.Errorf("unexpected content: %q", content)
+	}
+
+	entries, err := fs.ReadDir(fsys, "docs")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 1 || entries[0].Name() != "README.md" {
+		t.Errorf("unexpected entries: %v", entries)
+	}
+}
+
+func TestRawBackendSize(t *testing.T) {
+	mux, opt := setup(t)
+
+	// Compress responses if the client accepts it (the transport decompresses them transparently, dropping the length).
+	mux.HandleFunc("GET /raw/owner/repo/main/gzip.txt", func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			w.Write([]byte("hello"))
+
+			return
+		}
+
+		w.Header().Set("Content-Encoding", "gzip")
+
+		gz := gzip.NewWriter(w)
+		gz.Write([]byte("hello"))
+		gz.Close()
+	})
+	mux.HandleFunc("GET /raw/owner/repo/main/chunked.txt", func(w http.ResponseWriter, r *http.Request) {
+		w.(http.Flusher).Flush()
+		w.Write([]byte("hello"))
+	})
+
+	fsys := New(opt, WithRepository("owner", "repo"), WithRef("main"), WithRawBackend())
+
+	for _, name := range []string{"gzip.txt", "chunked.txt"} {
+		file, err := fsys.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		info, err := file.Stat()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if info.Size() != 5 {
+			t.Errorf("%s: unexpected size: %d", name, info.Size())
+		}
+
+		b := make([]byte, 3)
+		if _, err := file.(io.ReaderAt).ReadAt(b, 2); err != nil {
+			t.Fatal(err)
+		}
+
+		if string(b) != "llo" {
+			t.Errorf("%s: unexpected content: %q", name, b)
+		}
+
+		file.Close()
+	}
+}
+
+func TestRawBackendCredentials(t *testing.T) {
+	mux, opt := setup(t)
+
+	raw := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			t.Errorf("credentials sent to the raw content host: %q", auth)
+		}
+
+		w.Write([]byte("hello"))
+	}))
+	t.Cleanup(raw.Close)
+
+	mux.HandleFunc("GET /repos/owner/repo/contents/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			t.Error("expected credentials to be sent to the API")
+		}
+
+		w.Write([]byte(`[]`))
+	})
+
+	for _, cred := range []Option{WithToken("token"), WithTokenFunc(func(context.Context) (string, error) { return "token", nil })} {
+		fsys := New(opt, WithRepository("owner", "repo"), WithRawBackend(), cred)
+		fsys.rawURL, _ = url.Parse(raw.URL + "/")
+
+		if _, err := fs.ReadFile(fsys, "README.md"); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := fs.ReadDir(fsys, "."); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestWithMediaType(t *testing.T) {
+	mux, opt := setup(t)
+
+	mux.HandleFunc("GET /repos/owner/repo/contents/README.md", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") == MediaTypeHTML {
+			w.Write([]byte("<h1>repo</h1>"))
+
+			return
+		}
+
+		fileHandler("# repo")(w, r)
+	})
+
+	fsys := New(opt, WithRepository("owner", "repo"), WithMediaType(MediaTypeHTML))
+
+	content, err := fs.ReadFile(fsys, "README.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(content) != "<h1>repo</h1>" {
+		t.Errorf("unexpected content: %q", content)
+	}
+
+	f, err := fsys.Open("README.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Size() != int64(len("<h1>repo</h1>")) {
+		t.Errorf("unexpected size: %d", info.Size())
+	}
+
+	limited, err := New(opt, WithRepository("owner", "repo"), WithMediaType(MediaTypeHTML), WithMemoryLimit(4, 0)).Open("README.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer limited.Close()
+
+	if spooled, ok := limited.(*file).content.(*spooled); !ok || spooled.reader == nil {
+		t.Fatalf("expected content to be spooled, got %T", limited.(*file).content)
+	} else if _, ok := spooled.reader.(*os.File); !ok {
+		t.Errorf("expected content beyond the memory limit to be spilled to a temporary file, got %T", spooled.reader)
+	}
+
+	if content, err := io.ReadAll(limited); err != nil || string(content) != "<h1>repo</h1>" {
+		t.Errorf("unexpected content: %q, %v", content, err)
+	}
+
+	content, err = fs.ReadFile(New(opt, WithRepository("owner", "repo"), WithMediaType(MediaTypeHTML), WithMediaType("")), "README.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(content) != "# repo" {
+		t.Errorf("expected an empty media type to restore raw content, got %q", content)
+	}
+}
@@ -0,0 +1,111 @@
+package githubfs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy configures how failed requests are retried (see [WithRetry]).
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts of a request (including the first one).
+	//
+	// Defaults to 3.
+	MaxAttempts int
+
+	// MinBackoff is the delay before the first retry, doubled for every further retry.
+	//
+	// Defaults to 100ms.
+	MinBackoff time.Duration
+
+	// MaxBackoff caps the delay between retries.
+	//
+	// Defaults to 5s.
+	MaxBackoff time.Duration
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts == 0 {
+		p.MaxAttempts = 3
+	}
+
+	if p.MinBackoff == 0 {
+		p.MinBackoff = 100 * time.Millisecond
+	}
+
+	if p.MaxBackoff == 0 {
+		p.MaxBackoff = 5 * time.Second
+	}
+
+	p.MaxBackoff = max(p.MaxBackoff, p.MinBackoff)
+
+	return p
+}
+
+// backoff returns the delay before a retry (starting at 1) with jitter applied.
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	d := p.MaxBackoff
+	if shift := retry - 1; shift < 32 {
+		d = min(p.MinBackoff<<shift, p.MaxBackoff)
+	}
+
+	// Spread retries of concurrent requests over the second half of the delay
+	return d/2 + rand.N(d/2+1)
+}
+
+// retryTransport retries idempotent requests failing with server errors or transient network errors.
+type retryTransport struct {
+	base   http.RoundTripper
+	policy RetryPolicy
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return t.base.RoundTrip(req)
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if attempt >= t.policy.MaxAttempts || !t.retryable(req, resp, err) {
+			return resp, err
+		}
+
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		select {
+		case <-time.After(t.policy.backoff(attempt)):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+func (t *retryTransport) retryable(req *http.Request, resp *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+
+	if err == nil {
+		return resp.StatusCode >= http.StatusInternalServerError
+	}
+
+	// Deadlines of single attempts (see [WithTimeout])
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var opErr *net.OpError
+
+	return errors.As(err, &opErr)
+}
@@ -0,0 +1,79 @@
+package githubfs
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v74/github"
+)
+
+// revisions caches resolved git references (and their commit times, trees and archives) per repository.
+type revisions struct {
+	mu       sync.Mutex
+	m        map[string]string
+	times    map[string]time.Time
+	trees    map[string]*github.Tree
+	indexes  map[string]treeIndex
+	archives map[string]*repoArchive
+	blobs    map[string][]byte
+}
+
+func newRevisions() *revisions {
+	return &revisions{
+		m:        make(map[string]string),
+		times:    make(map[string]time.Time),
+		trees:    make(map[string]*github.Tree),
+		indexes:  make(map[string]treeIndex),
+		archives: make(map[string]*repoArchive),
+		blobs:    make(map[string][]byte),
+	}
+}
+
+// resolveRevision returns the git reference to use for requests against a repository.
+//
+// An empty string means the default branch of the repository.
+func (f *FS) resolveRevision(ctx context.Context, owner string, repo string) (string, error) {
+	if f.at.IsZero() {
+		return f.revision, nil
+	}
+
+	key := fmt.Sprintf("%s/%s@%s@%d", owner, repo, f.revision, f.at.UnixNano())
+
+	f.revisions.mu.Lock()
+	sha, ok := f.revisions.m[key]
+	f.revisions.mu.Unlock()
+
+	if ok {
+		return sha, nil
+	}
+
+	opts := &github.CommitsListOptions{
+		SHA:         f.revision,
+		Until:       f.at,
+		ListOptions: github.ListOptions{PerPage: 1},
+	}
+
+	commits, _, err := f.client.Repositories.ListCommits(f.ctxFn(ctx), owner, repo, opts)
+	if err := handleErr(err, "resolve", ref{owner: owner, repo: repo}.string()); err != nil {
+		return "", err
+	}
+
+	if len(commits) == 0 {
+		return "", &fs.PathError{
+			Op:   "resolve",
+			Path: ref{owner: owner, repo: repo}.string(),
+			Err:  fmt.Errorf("no commit found before %s: %w", f.at.Format(time.RFC3339), fs.ErrNotExist),
+		}
+	}
+
+	sha = commits[0].GetSHA()
+
+	f.revisions.mu.Lock()
+	f.revisions.m[key] = sha
+	f.revisions.mu.Unlock()
+
+	return sha, nil
+}
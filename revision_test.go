@@ -0,0 +1,62 @@
+package githubfs
+
+import (
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestWithRefAtTime(t *testing.T) {
+	mux, opt := setup(t)
+
+	var listed int
+
+	mux.HandleFunc("GET /repos/owner/repo/commits", func(w http.ResponseWriter, r *http.Request) {
+		listed++
+
+		if got, want := r.URL.Query().Get("until"), "2020-01-01T00:00:00Z"; got != want {
+			t.Errorf("unexpected until: got %q, want %q", got, want)
+		}
+
+		if got, want := r.URL.Query().Get("sha"), "main"; got != want {
+			t.Errorf("unexpected sha: got %q, want %q", got, want)
+		}
+
+		w.Write([]byte(`[{"sha":"abc123"}]`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/contents/README.md", func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.URL.Query().Get("ref"), "abc123"; got != want {
+			t.Errorf("unexpected ref: got %q, want %q", got, want)
+		}
+
+		w.Write([]byte(`{"type":"file","name":"README.md","encoding":"base64","content":"aGVsbG8=","size":5}`))
+	})
+
+	fsys := New(
+		opt,
+		WithRepository("owner", "repo"),
+		WithRef("main"),
+		WithRefAtTime(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)),
+	)
+
+	for range 2 {
+		file, err := fsys.Open("README.md")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		content, err := io.ReadAll(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(content) != "hello" {
+			t.Errorf("unexpected content: %q", content)
+		}
+	}
+
+	if listed != 1 {
+		t.Errorf("expected the ref to be resolved once, got %d", listed)
+	}
+}
@@ -0,0 +1,97 @@
+package githubfs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"strings"
+
+	"github.com/google/go-github/v74/github"
+)
+
+// snapshotVersion is the version of the snapshot format written by [FS.SaveSnapshot].
+const snapshotVersion = 1
+
+// snapshot is the serialized form of the immutable caches of a filesystem.
+type snapshot struct {
+	Version int `json:"version"`
+
+	// Trees are recursive trees at commit SHAs, keyed like revisions.trees.
+	Trees map[string]*github.Tree `json:"trees,omitempty"`
+
+	// Blobs are file contents keyed by git blob SHA.
+	Blobs map[string][]byte `json:"blobs,omitempty"`
+}
+
+// SaveSnapshot writes the immutable parts of the caches (trees at commit SHAs and file contents by blob SHA) to w,
+// so that they can be loaded by another process using [WithSnapshot].
+//
+// Responses that may change (e.g. listings at branches) are not included.
+// The snapshot is written as plain JSON, including file contents: it is not encoded by [Codec]s.
+func (f *FS) SaveSnapshot(w io.Writer) error {
+	s := snapshot{
+		Version: snapshotVersion,
+		Blobs:   f.cache.blobs(),
+	}
+
+	f.revisions.mu.Lock()
+	s.Trees = maps.Clone(f.revisions.trees)
+	f.revisions.mu.Unlock()
+
+	return json.NewEncoder(w).Encode(s)
+}
+
+// loadSnapshot seeds the caches from a snapshot.
+func (f *FS) loadSnapshot(s *snapshot) {
+	f.revisions.mu.Lock()
+	maps.Copy(f.revisions.trees, s.Trees)
+	f.revisions.mu.Unlock()
+
+	// Blobs expire like blobs fetched by this filesystem (see [WithCache]).
+	for sha, content := range s.Blobs {
+		f.cache.set(blobKey(sha), content, false)
+	}
+}
+
+// readSnapshot decodes a snapshot written by [FS.SaveSnapshot].
+//
+// Returns nil if the snapshot was written in an unknown format (e.g. by another version of this package).
+func readSnapshot(r io.Reader) (*snapshot, error) {
+	var s snapshot
+
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return nil, fmt.Errorf("decoding snapshot: %w", err)
+	}
+
+	if s.Version != snapshotVersion {
+		return nil, nil
+	}
+
+	return &s, nil
+}
+
+// blobs returns the file contents held by the cache keyed by git blob SHA.
+func (c *responseCache) blobs() map[string][]byte {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	blobs := make(map[string][]byte)
+	now := c.now()
+
+	for key, entry := range c.entries {
+		if !entry.expires.IsZero() && !now.Before(entry.expires) {
+			continue
+		}
+
+		if sha, ok := strings.CutPrefix(key, blobKey("")); ok {
+			blobs[sha] = entry.value.([]byte)
+		}
+	}
+
+	return blobs
+}
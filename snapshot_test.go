@@ -0,0 +1,125 @@
+package githubfs
+
+import (
+	"bytes"
+	"io/fs"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSnapshot(t *testing.T) {
+	mux, opt := setup(t)
+
+	const sha = "0123456789abcdef0123456789abcdef01234567"
+
+	var requests int
+
+	mux.HandleFunc("GET /repos/owner/repo/git/trees/{sha...}", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		w.Write([]byte(`{"tree":[
+			{"path":"README.md","type":"blob","sha":"` + blobHash([]byte("content")) + `"},
+			{"path":"docs","type":"tree"},
+			{"path":"docs/index.md","type":"blob"}
+		]}`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/git/blobs/{sha}", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		w.Write([]byte("content"))
+	})
+
+	fsys := New(opt, WithRepository("owner", "repo"), WithRef(sha), WithCache(time.Minute), WithGlobStrategy(GlobTree))
+
+	read := func(fsys fs.FS) {
+		t.Helper()
+
+		matches, err := fs.Glob(fsys, "*.md")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(matches) != 1 || matches[0] != "README.md" {
+			t.Errorf("unexpected matches: %v", matches)
+		}
+
+		content, err := fs.ReadFile(fsys, "README.md")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(content) != "content" {
+			t.Errorf("unexpected content: %q", content)
+		}
+	}
+
+	read(fsys)
+
+	if requests != 2 {
+		t.Fatalf("expected the tree and the blob to be fetched, got %d requests", requests)
+	}
+
+	var buf bytes.Buffer
+
+	if err := fsys.SaveSnapshot(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	requests = 0
+
+	read(New(opt, WithRepository("owner", "repo"), WithRef(sha), WithCache(time.Minute), WithGlobStrategy(GlobTree), WithSnapshot(&buf)))
+
+	if requests != 0 {
+		t.Errorf("expected reads to be served from the snapshot, got %d requests", requests)
+	}
+
+	// Snapshots in an unknown format are ignored
+	read(New(opt, WithRepository("owner", "repo"), WithRef(sha), WithCache(time.Minute), WithGlobStrategy(GlobTree), WithSnapshot(strings.NewReader(`{"version":0}`))))
+
+	if requests != 2 {
+		t.Errorf("expected a snapshot in an unknown format to be ignored, got %d requests", requests)
+	}
+
+	// Snapshots that cannot be decoded are reported
+	if _, err := NewFS(opt, WithRepository("owner", "repo"), WithSnapshot(strings.NewReader("invalid"))); err == nil {
+		t.Error("expected an invalid snapshot to be reported")
+	}
+}
+
+func TestSnapshotBlobExpiry(t *testing.T) {
+	mux, opt := setup(t)
+
+	var requests int
+
+	mux.HandleFunc("GET /repos/owner/repo/git/blobs/{sha}", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		w.Write([]byte("content"))
+	})
+
+	sha := blobHash([]byte("content"))
+	snapshot := `{"version":` + strconv.Itoa(snapshotVersion) + `,"blobs":{"` + sha + `":"Y29udGVudA=="}}`
+
+	fsys := New(opt, WithRepository("owner", "repo"), WithCache(time.Minute), WithSnapshot(strings.NewReader(snapshot)))
+
+	for _, advance := range []time.Duration{0, time.Minute} {
+		now := time.Now().Add(advance)
+		fsys.cache.now = func() time.Time { return now }
+
+		content, err := fsys.getBlob(t.Context(), ref{owner: "owner", repo: "repo"}, sha)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(content) != "content" {
+			t.Errorf("unexpected content: %q", content)
+		}
+	}
+
+	if requests != 1 {
+		t.Errorf("expected the blob to expire after the cache TTL, got %d requests", requests)
+	}
+}
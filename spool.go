@@ -0,0 +1,182 @@
+package githubfs
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// memoryLimit caps content buffered in memory (see [WithMemoryLimit]).
+type memoryLimit struct {
+	perFile int64
+	total   int64
+	used    atomic.Int64
+}
+
+// reserve reserves n bytes of memory for a file that already buffered size bytes.
+//
+// A nil limit reserves any amount of memory.
+func (l *memoryLimit) reserve(size int64, n int64) bool {
+	if l == nil {
+		return true
+	}
+
+	if l.perFile > 0 && size+n > l.perFile {
+		return false
+	}
+
+	if used := l.used.Add(n); l.total > 0 && used > l.total {
+		l.used.Add(-n)
+
+		return false
+	}
+
+	return true
+}
+
+func (l *memoryLimit) release(n int64) {
+	if l == nil {
+		return
+	}
+
+	l.used.Add(-n)
+}
+
+// allows reports whether content of the given size may be held in memory beyond the read that produced it
+// (e.g. in a cache).
+func (l *memoryLimit) allows(size int) bool {
+	return l == nil || l.perFile <= 0 || int64(size) <= l.perFile
+}
+
+// spool buffers content written to it in memory up to the memory limit,
+// then moves it to a temporary file.
+type spool struct {
+	limit *memoryLimit
+	buf   bytes.Buffer
+	file  *os.File
+}
+
+func newSpool(limit *memoryLimit) *spool {
+	return &spool{limit: limit}
+}
+
+func (s *spool) Write(p []byte) (int, error) {
+	if s.file == nil && s.limit.reserve(int64(s.buf.Len()), int64(len(p))) {
+		return s.buf.Write(p)
+	}
+
+	if s.file == nil {
+		file, err := os.CreateTemp("", "githubfs-*")
+		if err != nil {
+			return 0, err
+		}
+
+		s.file = file
+
+		// Memory is released as soon as content spills to disk.
+		n := s.buf.Len()
+		_, err = s.buf.WriteTo(file)
+		s.limit.release(int64(n))
+		s.buf = bytes.Buffer{}
+
+		if err != nil {
+			s.discard()
+
+			return 0, err
+		}
+	}
+
+	return s.file.Write(p)
+}
+
+// spoolContent buffers content read from r in memory up to the memory limit and in a temporary file beyond it.
+func (f *FS) spoolContent(r io.Reader) (*spooled, error) {
+	buf := newSpool(f.memory)
+
+	if _, err := io.Copy(buf, r); err != nil {
+		buf.discard()
+
+		return nil, err
+	}
+
+	return buf.buffer()
+}
+
+// buffer returns the spooled content.
+func (s *spool) buffer() (*spooled, error) {
+	if s.file == nil {
+		return &spooled{
+			reader: bytes.NewReader(s.buf.Bytes()),
+			size:   int64(s.buf.Len()),
+			close: func() error {
+				s.limit.release(int64(s.buf.Len()))
+
+				return nil
+			},
+		}, nil
+	}
+
+	size, err := s.file.Seek(0, io.SeekEnd)
+	if err == nil {
+		_, err = s.file.Seek(0, io.SeekStart)
+	}
+
+	if err != nil {
+		s.discard()
+
+		return nil, err
+	}
+
+	return &spooled{reader: s.file, size: size, close: s.discard}, nil
+}
+
+// discard releases the resources held by the spool.
+func (s *spool) discard() error {
+	if s.file == nil {
+		s.limit.release(int64(s.buf.Len()))
+
+		return nil
+	}
+
+	err := s.file.Close()
+	os.Remove(s.file.Name())
+
+	return err
+}
+
+// spooled is content buffered by a spool (in memory or in a temporary file).
+type spooled struct {
+	reader interface {
+		io.ReadSeeker
+		io.ReaderAt
+	}
+	size int64
+
+	once  sync.Once
+	close func() error
+}
+
+func (s *spooled) Read(p []byte) (int, error) {
+	return s.reader.Read(p)
+}
+
+func (s *spooled) Seek(offset int64, whence int) (int64, error) {
+	return s.reader.Seek(offset, whence)
+}
+
+func (s *spooled) ReadAt(p []byte, off int64) (int, error) {
+	return s.reader.ReadAt(p, off)
+}
+
+// Close releases the memory or removes the temporary file holding the content.
+func (s *spooled) Close() error {
+	var err error
+
+	s.once.Do(func() {
+		err = s.close()
+	})
+
+	return err
+}
@@ -0,0 +1,91 @@
+package githubfs
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path"
+
+	"github.com/google/go-github/v74/github"
+)
+
+// StatMany returns the file info of many paths with as few requests as possible.
+//
+// Paths in the same directory are looked up in a single listing of the directory
+// (or in the prefetched tree if [WithEagerTree] is enabled).
+// Paths that do not exist are missing from the result.
+func (f *FS) StatMany(ctx context.Context, paths ...string) (map[string]fs.FileInfo, error) {
+	type dirKey struct {
+		parent   ref
+		revision string
+	}
+
+	infos := make(map[string]fs.FileInfo, len(paths))
+
+	var dirs []dirKey
+	names := make(map[dirKey][]string)
+
+	for _, name := range paths {
+		r, err := f.resolve("stat", name)
+		if err != nil {
+			return nil, err
+		}
+
+		// Owners, repositories and prefetched trees are not looked up in listings.
+		if r.repo == "" || r.path == "" || f.eager || f.archives {
+			info, err := f.stat(ctx, name)
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+
+			infos[name] = info
+
+			continue
+		}
+
+		revision, err := f.resolveRevision(ctx, r.owner, r.repo)
+		if err != nil {
+			return nil, err
+		}
+
+		key := dirKey{parent: r.parent(), revision: revision}
+		if _, ok := names[key]; !ok {
+			dirs = append(dirs, key)
+		}
+
+		names[key] = append(names[key], name)
+	}
+
+	for _, key := range dirs {
+		_, dirContent, err := f.getContents(ctx, key.parent, key.revision)
+		if isNotFound(err) {
+			continue
+		} else if err := handleErr(err, "stat", key.parent.string()); err != nil {
+			return nil, err
+		}
+
+		if len(dirContent) >= contentsLimit {
+			dirContent, err = f.getTreeContents(ctx, "stat", key.parent, key.revision)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		entries := make(map[string]*github.RepositoryContent, len(dirContent))
+		for _, content := range dirContent {
+			entries[content.GetName()] = content
+		}
+
+		for _, name := range names[key] {
+			r, _ := f.resolve("stat", name)
+
+			if content, ok := entries[path.Base(r.path)]; ok {
+				infos[name] = f.contentInfo(ctx, r, key.revision, content)
+			}
+		}
+	}
+
+	return infos, nil
+}
@@ -0,0 +1,49 @@
+package githubfs
+
+import (
+	"maps"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestStatMany(t *testing.T) {
+	mux, opt := setup(t)
+
+	var listings []string
+
+	mux.HandleFunc("GET /repos/owner/repo/contents/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		listings = append(listings, r.PathValue("path"))
+
+		switch r.PathValue("path") {
+		case "":
+			w.Write([]byte(`[
+				{"type":"file","name":"README.md","path":"README.md","size":6},
+				{"type":"dir","name":"docs","path":"docs"}
+			]`))
+		case "docs":
+			w.Write([]byte(`[{"type":"file","name":"index.md","path":"docs/index.md","size":42}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	fsys := New(opt, WithRepository("owner", "repo"))
+
+	infos, err := fsys.StatMany(t.Context(), "README.md", "docs", "LICENSE", "docs/index.md", "docs/missing.md", "missing/file.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := slices.Sorted(maps.Keys(infos)), []string{"README.md", "docs", "docs/index.md"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected paths: got %v, want %v", got, want)
+	}
+
+	if !infos["docs"].IsDir() || infos["docs/index.md"].Size() != 42 {
+		t.Errorf("unexpected file info: %v %d", infos["docs"].IsDir(), infos["docs/index.md"].Size())
+	}
+
+	if want := []string{"", "docs", "missing"}; !slices.Equal(listings, want) {
+		t.Errorf("expected one listing per directory, got %v", listings)
+	}
+}
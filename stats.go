@@ -0,0 +1,177 @@
+package githubfs
+
+import (
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Stats holds counters of the requests sent by a filesystem (see [FS.Stats]).
+type Stats struct {
+	// Requests counts requests sent by endpoint (e.g. "GET /repos/{owner}/{repo}/contents").
+	//
+	// Requests to hosts other than the API (e.g. raw content or LFS objects) are counted by host.
+	Requests map[string]int
+
+	// CacheHits and CacheMisses count lookups in the caches configured by
+	// [WithCache], [WithMetadataCache] and [WithNegativeCache].
+	CacheHits   int
+	CacheMisses int
+
+	// BytesDownloaded is the size of response bodies read so far.
+	BytesDownloaded int64
+
+	// NotModified counts 304 responses to conditional requests (see [WithConditionalRequests]).
+	NotModified int
+}
+
+// stats collects the counters returned by [FS.Stats] (shared between clones).
+type stats struct {
+	mu sync.Mutex
+	s  Stats
+}
+
+func newStats() *stats {
+	return &stats{s: Stats{Requests: make(map[string]int)}}
+}
+
+func (s *stats) snapshot() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.s
+	c.Requests = maps.Clone(s.s.Requests)
+
+	return c
+}
+
+func (s *stats) update(fn func(s *Stats)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fn(&s.s)
+}
+
+// cacheLookup records the outcome of a cache lookup.
+func (s *stats) cacheLookup(hit bool) {
+	s.update(func(s *Stats) {
+		if hit {
+			s.CacheHits++
+		} else {
+			s.CacheMisses++
+		}
+	})
+}
+
+// Stats returns counters of the requests sent (and the responses served from caches) by the filesystem
+// and the filesystems sharing its caches (e.g. those returned by [FS.Sub]).
+//
+// Use it to tune caching options and to predict rate limit consumption.
+func (f *FS) Stats() Stats {
+	return f.stats.snapshot()
+}
+
+// recordCacheLookup records the outcome of a lookup in caches, unless none of them is enabled.
+func (f *FS) recordCacheLookup(hit bool, caches ...*responseCache) {
+	if !slices.ContainsFunc(caches, func(c *responseCache) bool { return c != nil }) {
+		return
+	}
+
+	f.stats.cacheLookup(hit)
+}
+
+// statsTransport counts requests and downloaded bytes.
+type statsTransport struct {
+	base    http.RoundTripper
+	stats   *stats
+	baseURL *url.URL
+}
+
+func (t *statsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	endpoint := req.Method + " " + t.endpoint(req.URL)
+
+	t.stats.update(func(s *Stats) {
+		s.Requests[endpoint]++
+	})
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified {
+		t.stats.update(func(s *Stats) {
+			s.NotModified++
+		})
+	}
+
+	resp.Body = &countingBody{ReadCloser: resp.Body, stats: t.stats}
+
+	return resp, nil
+}
+
+// endpoint returns the endpoint a request URL belongs to, with owner and repository names replaced by placeholders.
+func (t *statsTransport) endpoint(u *url.URL) string {
+	if t.baseURL == nil || u.Host != t.baseURL.Host {
+		return u.Host
+	}
+
+	p, ok := strings.CutPrefix(u.Path, t.baseURL.Path)
+	if !ok {
+		// The GraphQL API is served next to the REST API on GitHub Enterprise Server
+		if path.Base(u.Path) == "graphql" {
+			return "/graphql"
+		}
+
+		return u.Path
+	}
+
+	segments := strings.Split(p, "/")
+
+	switch {
+	case segments[0] == "repos" && len(segments) >= 3:
+		endpoint := "/repos/{owner}/{repo}"
+
+		if len(segments) > 3 {
+			endpoint += "/" + segments[3]
+		}
+
+		// Git database endpoints (e.g. git/trees)
+		if len(segments) > 4 && segments[3] == "git" {
+			endpoint += "/" + segments[4]
+		}
+
+		return endpoint
+
+	case (segments[0] == "users" || segments[0] == "orgs") && len(segments) >= 2:
+		return path.Join("/"+segments[0], "{owner}", strings.Join(segments[2:], "/"))
+
+	case segments[0] == "search" && len(segments) >= 2:
+		return "/search/" + segments[1]
+	}
+
+	return "/" + segments[0]
+}
+
+// countingBody counts the bytes read from a response body.
+type countingBody struct {
+	io.ReadCloser
+	stats *stats
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+
+	if n > 0 {
+		b.stats.update(func(s *Stats) {
+			s.BytesDownloaded += int64(n)
+		})
+	}
+
+	return n, err
+}
@@ -0,0 +1,69 @@
+package githubfs
+
+import (
+	"io/fs"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	mux, opt := setup(t)
+
+	mux.HandleFunc("GET /repos/owner/repo/contents/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("path") == "" {
+			w.Write([]byte(`[{"type":"file","name":"README.md","path":"README.md","size":7}]`))
+
+			return
+		}
+
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+
+		w.Header().Set("ETag", `"v1"`)
+		fileHandler("content")(w, r)
+	})
+
+	fsys := New(opt, WithRepository("owner", "repo"), WithMetadataCache(time.Minute), WithConditionalRequests())
+
+	for range 2 {
+		if _, err := fs.ReadDir(fsys, "."); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := fs.ReadFile(fsys, "README.md"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stats := fsys.Stats()
+
+	if got := stats.Requests["GET /repos/{owner}/{repo}/contents"]; got != 3 {
+		t.Errorf("unexpected number of requests: %d (%v)", got, stats.Requests)
+	}
+
+	if stats.CacheHits != 1 || stats.CacheMisses != 1 {
+		t.Errorf("unexpected cache lookups: %d hits, %d misses", stats.CacheHits, stats.CacheMisses)
+	}
+
+	if stats.NotModified != 1 {
+		t.Errorf("unexpected number of 304 responses: %d", stats.NotModified)
+	}
+
+	if stats.BytesDownloaded == 0 {
+		t.Error("expected downloaded bytes to be counted")
+	}
+
+	// Filesystems returned by Sub share the counters
+	sub, err := fs.Sub(fsys, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := sub.(*FS).Stats().Requests; len(got) != len(stats.Requests) {
+		t.Errorf("expected sub filesystems to share counters: %v", got)
+	}
+}
@@ -0,0 +1,43 @@
+package githubfs
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// timeoutTransport applies a deadline to each request (see [WithTimeout]).
+//
+// Like [http.Client.Timeout], the deadline also covers reading the response body.
+type timeoutTransport struct {
+	base    http.RoundTripper
+	timeout time.Duration
+}
+
+func (t *timeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+
+		return nil, err
+	}
+
+	resp.Body = &timeoutBody{ReadCloser: resp.Body, cancel: cancel}
+
+	return resp, nil
+}
+
+// timeoutBody releases the deadline of a request once its response body is closed.
+type timeoutBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *timeoutBody) Close() error {
+	defer b.cancel()
+
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,220 @@
+package githubfs
+
+import (
+	"context"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/google/go-github/v74/github"
+)
+
+// getTree fetches the git tree of the directory r points to.
+func (f *FS) getTree(ctx context.Context, op string, r ref, revision string, recursive bool) (*github.Tree, error) {
+	tree, _, err := f.client.Git.GetTree(f.ctxFn(ctx), r.owner, r.repo, escapeTreeish(treeish(revision, r.path)), recursive)
+	if err := handleErr(err, op, r.string()); err != nil {
+		return nil, err
+	}
+
+	return tree, nil
+}
+
+// getCompleteTree fetches the recursive tree of the directory r points to.
+//
+// Trees at commit SHAs are cached (see [FS.cacheTree]).
+//
+// The API truncates recursive trees above a certain size:
+// truncated trees are completed by fetching subtrees individually (see [FS.completeTreeEntries]).
+func (f *FS) getCompleteTree(ctx context.Context, op string, r ref, revision string) (*github.Tree, error) {
+	if tree := f.cachedTree(r, revision); tree != nil {
+		return tree, nil
+	}
+
+	tree, err := f.getTree(ctx, op, r, revision, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if tree.GetTruncated() {
+		entries, err := f.completeTreeEntries(ctx, op, r, tree, "")
+		if err != nil {
+			return nil, err
+		}
+
+		tree = &github.Tree{
+			SHA:       tree.SHA,
+			Entries:   entries,
+			Truncated: github.Ptr(false),
+		}
+	}
+
+	f.cacheTree(r, revision, tree)
+
+	return tree, nil
+}
+
+// getSubtreeEntries fetches the entries of a tree recursively, with paths prefixed by prefix.
+func (f *FS) getSubtreeEntries(ctx context.Context, op string, r ref, sha string, prefix string) ([]*github.TreeEntry, error) {
+	tree, _, err := f.client.Git.GetTree(f.ctxFn(ctx), r.owner, r.repo, sha, true)
+	if err := handleErr(err, op, ref{owner: r.owner, repo: r.repo, path: path.Join(r.path, prefix)}.string()); err != nil {
+		return nil, err
+	}
+
+	return f.completeTreeEntries(ctx, op, r, tree, prefix)
+}
+
+// completeTreeEntries returns the entries of a fetched recursive tree, with paths prefixed by prefix.
+//
+// If the tree is truncated, its top-level entries are listed by SHA (the truncated listing may be missing some)
+// and subtrees are fetched individually, instead of fetching the same truncated tree again.
+func (f *FS) completeTreeEntries(ctx context.Context, op string, r ref, tree *github.Tree, prefix string) ([]*github.TreeEntry, error) {
+	truncated := tree.GetTruncated()
+
+	if truncated {
+		var err error
+
+		tree, _, err = f.client.Git.GetTree(f.ctxFn(ctx), r.owner, r.repo, tree.GetSHA(), false)
+		if err := handleErr(err, op, ref{owner: r.owner, repo: r.repo, path: path.Join(r.path, prefix)}.string()); err != nil {
+			return nil, err
+		}
+	}
+
+	var entries []*github.TreeEntry
+
+	for _, entry := range tree.Entries {
+		e := *entry
+		e.Path = github.Ptr(path.Join(prefix, entry.GetPath()))
+
+		entries = append(entries, &e)
+
+		if truncated && entry.GetType() == "tree" {
+			subtree, err := f.getSubtreeEntries(ctx, op, r, entry.GetSHA(), e.GetPath())
+			if err != nil {
+				return nil, err
+			}
+
+			entries = append(entries, subtree...)
+		}
+	}
+
+	return entries, nil
+}
+
+// cachedTree returns the recursive tree of the directory r points to, if it has been fetched before.
+func (f *FS) cachedTree(r ref, revision string) *github.Tree {
+	if !isCommitSHA(revision) {
+		return nil
+	}
+
+	f.revisions.mu.Lock()
+	defer f.revisions.mu.Unlock()
+
+	return f.revisions.trees[r.string()+"@"+revision]
+}
+
+// cacheTree caches the recursive tree of the directory r points to.
+//
+// Only trees at commit SHAs are cached: branches and tags can move.
+func (f *FS) cacheTree(r ref, revision string, tree *github.Tree) {
+	if !isCommitSHA(revision) {
+		return
+	}
+
+	f.revisions.mu.Lock()
+	defer f.revisions.mu.Unlock()
+
+	f.revisions.trees[r.string()+"@"+revision] = tree
+}
+
+// escapeTreeish escapes a tree-ish expression for use in a URL path,
+// so paths containing characters such as "#", "?" or "%" are not cut off or misread.
+func escapeTreeish(expr string) string {
+	segments := strings.Split(expr, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// isCommitSHA reports whether a git reference is a full commit SHA.
+func isCommitSHA(revision string) bool {
+	if len(revision) != 40 {
+		return false
+	}
+
+	return !strings.ContainsFunc(revision, func(r rune) bool {
+		return !('0' <= r && r <= '9' || 'a' <= r && r <= 'f')
+	})
+}
+
+// contentsLimit is the maximum number of entries the Contents API returns for a directory.
+const contentsLimit = 1000
+
+// getTreeContents lists the directory r points to using the Git Trees API, in the format of the Contents API.
+//
+// Used for directories the Contents API truncates.
+// Listings are cached and shared between concurrent identical requests like [FS.getContents].
+func (f *FS) getTreeContents(ctx context.Context, op string, r ref, revision string) ([]*github.RepositoryContent, error) {
+	key := "tree contents " + r.string() + "@" + revision
+
+	v, ok := f.metadata.get(key)
+	if !ok {
+		v, ok = f.cache.get(key)
+	}
+
+	f.recordCacheLookup(ok, f.metadata, f.cache)
+
+	if ok {
+		return v.([]*github.RepositoryContent), nil
+	}
+
+	v, err, _ := f.calls.Do(key, func() (any, error) {
+		contents, err := f.fetchTreeContents(ctx, op, r, revision)
+		if err != nil {
+			return nil, err
+		}
+
+		f.cache.set(key, contents, isCommitSHA(revision))
+		f.metadata.set(key, contents, isCommitSHA(revision))
+
+		return contents, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return v.([]*github.RepositoryContent), nil
+}
+
+// fetchTreeContents fetches the listing of the directory r points to (see [FS.getTreeContents]).
+func (f *FS) fetchTreeContents(ctx context.Context, op string, r ref, revision string) ([]*github.RepositoryContent, error) {
+	tree, err := f.getTree(ctx, op, r, revision, false)
+	if err != nil {
+		return nil, err
+	}
+
+	contents := make([]*github.RepositoryContent, 0, len(tree.Entries))
+
+	for _, entry := range tree.Entries {
+		content := &github.RepositoryContent{
+			Name: entry.Path,
+			Path: github.Ptr(path.Join(r.path, entry.GetPath())),
+			SHA:  entry.SHA,
+			Size: entry.Size,
+		}
+
+		switch entry.GetType() {
+		case "tree":
+			content.Type = github.Ptr("dir")
+		case "commit":
+			content.Type = github.Ptr("submodule")
+		default:
+			content.Type = github.Ptr("file")
+		}
+
+		contents = append(contents, content)
+	}
+
+	return contents, nil
+}
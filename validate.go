@@ -0,0 +1,177 @@
+package githubfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ValidationReport is the result of [FS.Validate].
+type ValidationReport struct {
+	Checks []ValidationCheck
+}
+
+// ValidationCheck is the outcome of a single check performed by [FS.Validate].
+type ValidationCheck struct {
+	// Name identifies the check (e.g. "owner" or "repository").
+	Name string
+
+	// Skipped is true when the check does not apply to the configuration.
+	Skipped bool
+
+	// Err is the reason the check failed (nil if it passed or was skipped).
+	Err error
+}
+
+// OK reports whether all checks passed (or were skipped).
+func (r *ValidationReport) OK() bool {
+	return r.Err() == nil
+}
+
+// Err returns the errors of all failed checks joined together (or nil if there are none).
+func (r *ValidationReport) Err() error {
+	var errs []error
+
+	for _, check := range r.Checks {
+		if check.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", check.Name, check.Err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (r *ValidationReport) add(name string, err error) {
+	r.Checks = append(r.Checks, ValidationCheck{Name: name, Err: err})
+}
+
+func (r *ValidationReport) skip(name string) {
+	r.Checks = append(r.Checks, ValidationCheck{Name: name, Skipped: true})
+}
+
+// Validate performs cheap checks against the configured owner, repository and ref without reading any content,
+// so that misconfigurations can be detected at startup instead of at the first request.
+func (f *FS) Validate(ctx context.Context) *ValidationReport {
+	report := &ValidationReport{}
+
+	if f.ref.owner == "" {
+		report.skip("owner")
+		report.skip("repository")
+		report.skip("ref")
+		report.skip("scopes")
+
+		return report
+	}
+
+	_, _, err := f.client.Users.Get(f.ctxFn(ctx), f.ref.owner)
+	report.add("owner", handleErr(err, "validate", "/"+f.ref.owner))
+
+	if f.ref.repo == "" {
+		report.skip("repository")
+		report.skip("ref")
+		report.skip("scopes")
+
+		return report
+	}
+
+	r := ref{owner: f.ref.owner, repo: f.ref.repo}
+
+	repo, resp, err := f.client.Repositories.Get(f.ctxFn(ctx), r.owner, r.repo)
+	report.add("repository", handleErr(err, "validate", r.string()))
+
+	switch {
+	case !f.at.IsZero():
+		_, refErr := f.resolveRevision(ctx, r.owner, r.repo)
+		report.add("ref", refErr)
+
+	case f.revision != "":
+		_, _, refErr := f.client.Repositories.GetCommitSHA1(f.ctxFn(ctx), r.owner, r.repo, f.revision, "")
+		report.add("ref", handleErr(refErr, "validate", r.string()+"@"+f.revision))
+
+	default:
+		report.skip("ref")
+	}
+
+	// Only classic tokens report their scopes.
+	if err != nil || resp.Header.Values("X-OAuth-Scopes") == nil {
+		report.skip("scopes")
+
+		return report
+	}
+
+	var scopeErr error
+
+	if repo.GetPrivate() && !hasScope(resp.Header.Get("X-OAuth-Scopes"), "repo") {
+		scopeErr = fmt.Errorf("token is missing the %q scope required to read private repository %s", "repo", r.string())
+	}
+
+	report.add("scopes", scopeErr)
+
+	return report
+}
+
+func hasScope(header string, scope string) bool {
+	return slices.ContainsFunc(strings.Split(header, ","), func(s string) bool {
+		return strings.TrimSpace(s) == scope
+	})
+}
+
+// validateOptions reports invalid configurations, including options that conflict with each other (see [NewFS]).
+func (f *FS) validateOptions() error {
+	errs := append([]error{f.err}, f.invalid...)
+
+	conflict := func(a string, b string) {
+		errs = append(errs, fmt.Errorf("%s conflicts with %s", a, b))
+	}
+
+	// The archive backend serves every read: other backends would be ignored
+	if f.archives {
+		if f.eager {
+			conflict("WithArchiveBackend", "WithEagerTree")
+		}
+
+		if f.graphql {
+			conflict("WithArchiveBackend", "WithGraphQLBackend")
+		}
+
+		if f.rawBackend {
+			conflict("WithArchiveBackend", "WithRawBackend")
+		}
+	}
+
+	if f.mediaType != "" {
+		switch {
+		case f.archives:
+			conflict("WithMediaType", "WithArchiveBackend")
+		case f.graphql:
+			conflict("WithMediaType", "WithGraphQLBackend")
+		case f.rawBackend:
+			conflict("WithMediaType", "WithRawBackend")
+		}
+	}
+
+	if f.tokenSource != nil && f.tokenFunc != nil {
+		conflict("WithTokenSource", "WithTokenFunc")
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	return nil
+}
+
+func validateTTL(f *FS, ttl time.Duration) {
+	if ttl <= 0 {
+		f.invalidOption(fmt.Errorf("cache TTL must be positive: %s", ttl))
+	}
+}
+
+// invalidOption records an invalid option (see [NewFS]).
+func (f *FS) invalidOption(err error) {
+	// The slice is shared between clones
+	f.invalid = append(slices.Clip(f.invalid), err)
+}
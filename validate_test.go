@@ -0,0 +1,113 @@
+package githubfs
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	mux, opt := setup(t)
+
+	mux.HandleFunc("GET /users/owner", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"login":"owner"}`))
+	})
+	mux.HandleFunc("GET /repos/owner/private", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-OAuth-Scopes", "read:org, gist")
+		w.Write([]byte(`{"name":"private","private":true}`))
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		report := New(opt, WithOwner("owner")).Validate(t.Context())
+
+		if !report.OK() {
+			t.Fatalf("expected report to pass, got %v", report.Err())
+		}
+
+		if len(report.Checks) != 4 || !report.Checks[1].Skipped {
+			t.Errorf("expected repository check to be skipped: %+v", report.Checks)
+		}
+	})
+
+	t.Run("missing repository", func(t *testing.T) {
+		report := New(opt, WithRepository("owner", "missing")).Validate(t.Context())
+
+		if err := report.Err(); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("expected fs.ErrNotExist, got %v", err)
+		}
+	})
+
+	t.Run("missing scope", func(t *testing.T) {
+		report := New(opt, WithRepository("owner", "private")).Validate(t.Context())
+
+		if report.OK() {
+			t.Fatal("expected scopes check to fail")
+		}
+
+		if check := report.Checks[3]; check.Name != "scopes" || check.Err == nil {
+			t.Errorf("unexpected check: %+v", check)
+		}
+	})
+}
+
+func TestWithVerify(t *testing.T) {
+	mux, opt := setup(t)
+
+	mux.HandleFunc("GET /users/owner", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"login":"owner"}`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"name":"repo"}`))
+	})
+	mux.HandleFunc("GET /repos/owner/secret", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"message":"Forbidden"}`))
+	})
+
+	if _, err := NewFS(opt, WithRepository("owner", "repo"), WithVerify()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if _, err := NewFS(opt, WithRepository("owner", "missing"), WithVerify()); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected fs.ErrNotExist, got %v", err)
+	}
+
+	if _, err := NewFS(opt, WithRepository("owner", "secret"), WithVerify()); !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("expected fs.ErrPermission, got %v", err)
+	}
+}
+
+func TestNewFS(t *testing.T) {
+	if _, err := NewFS(WithRepository("owner", "repo")); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{"nil client", []Option{WithClient(nil)}},
+		{"invalid base URL", []Option{WithBaseURL("://invalid", "")}},
+		{"invalid proxy URL", []Option{WithProxy("://invalid")}},
+		{"repository without owner", []Option{WithRepository("", "repo")}},
+		{"unknown line ending", []Option{WithLineEndingNormalization(LineEnding(42))}},
+		{"zero cache TTL", []Option{WithCache(0)}},
+		{"zero concurrency", []Option{WithMaxConcurrency(0)}},
+		{"negative timeout", []Option{WithTimeout(-time.Second)}},
+		{"negative retry attempts", []Option{WithRetry(RetryPolicy{MaxAttempts: -1})}},
+		{"page size out of range", []Option{WithPerPage(101)}},
+		{"negative memory limit", []Option{WithMemoryLimit(-1, 0)}},
+		{"archive and raw backends", []Option{WithArchiveBackend(), WithRawBackend()}},
+		{"media type and archive backend", []Option{WithMediaType(MediaTypeHTML), WithArchiveBackend()}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := NewFS(test.opts...); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
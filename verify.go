@@ -0,0 +1,160 @@
+package githubfs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"time"
+)
+
+// BackendMismatch is a difference between the content or metadata of a file read through different backends
+// (reported by [FS.VerifyBackends]).
+type BackendMismatch struct {
+	// Path is the path of the file relative to the verified root.
+	Path string
+
+	// Backend is the backend that disagrees with the Git Trees API.
+	Backend string
+
+	// Reason describes the difference.
+	Reason string
+}
+
+func (m *BackendMismatch) Error() string {
+	return fmt.Sprintf("%s: %s backend: %s", m.Path, m.Backend, m.Reason)
+}
+
+// VerifyBackends reads every file of the subtree at root through each backend
+// and compares content and metadata to the git objects returned by the Git Trees and Blobs APIs.
+//
+// Backends:
+//   - contents: the Contents API (default for Open)
+//   - raw: the Contents API with the raw media type (default for ReadFile)
+//   - raw-host: the raw content host (only if [WithRawBackend] is configured)
+//
+// Files are compared as stored in git (options transforming content, like [WithLFS], are not applied).
+// Symbolic links and submodules are skipped.
+//
+// Differences are returned as [*BackendMismatch] errors joined together.
+// Verification costs several API requests per file, so it is meant for tests and troubleshooting.
+func (f *FS) VerifyBackends(ctx context.Context, root string) error {
+	r, err := f.resolve("verify", root)
+	if err != nil {
+		return err
+	}
+
+	if r.repo == "" {
+		return &fs.PathError{Op: "verify", Path: root, Err: fmt.Errorf("root must be inside a repository: %w", fs.ErrInvalid)}
+	}
+
+	revision, err := f.resolveRevision(ctx, r.owner, r.repo)
+	if err != nil {
+		return err
+	}
+
+	tree, err := f.getCompleteTree(ctx, "verify", r, revision)
+	if err != nil {
+		return err
+	}
+
+	// Read content as stored in git
+	v := f.clone(r)
+	v.revision = revision
+	v.at = time.Time{}
+	v.lfs = false
+	v.rawBackend = false
+	v.lineEnding = 0
+
+	var errs []error
+
+	mismatch := func(name string, backend string, format string, args ...any) {
+		errs = append(errs, &BackendMismatch{Path: name, Backend: backend, Reason: fmt.Sprintf(format, args...)})
+	}
+
+	for _, entry := range tree.Entries {
+		if entry.GetType() != "blob" || entry.GetMode() == "120000" {
+			continue
+		}
+
+		name := entry.GetPath()
+
+		want, _, err := f.client.Git.GetBlobRaw(f.ctxFn(ctx), r.owner, r.repo, entry.GetSHA())
+		if err := handleErr(err, "verify", r.join(name).string()); err != nil {
+			return err
+		}
+
+		wantMode := fs.FileMode(0o644)
+		if entry.GetMode() == "100755" {
+			wantMode = 0o755
+		}
+
+		// Contents API
+		content, info, err := readAll(v.open(ctx, name))
+		if err != nil {
+			mismatch(name, "contents", "%v", err)
+		} else {
+			if !bytes.Equal(content, want) {
+				mismatch(name, "contents", "content differs")
+			}
+
+			if info.Size() != int64(len(want)) {
+				mismatch(name, "contents", "size %d, want %d", info.Size(), len(want))
+			}
+
+			if info.Mode() != wantMode {
+				mismatch(name, "contents", "mode %v, want %v", info.Mode(), wantMode)
+			}
+		}
+
+		// Raw media type
+		if content, err := v.readFile(ctx, name); err != nil {
+			mismatch(name, "raw", "%v", err)
+		} else if !bytes.Equal(content, want) {
+			mismatch(name, "raw", "content differs")
+		}
+
+		// Raw content host
+		if f.rawBackend {
+			file, ok, err := v.openRawBackend(ctx, r.join(name), revision)
+
+			switch {
+			case err != nil:
+				mismatch(name, "raw-host", "%v", err)
+			case !ok:
+				mismatch(name, "raw-host", "file not found")
+			default:
+				content, _, err := readAll(file, nil)
+				if err != nil {
+					mismatch(name, "raw-host", "%v", err)
+				} else if !bytes.Equal(content, want) {
+					mismatch(name, "raw-host", "content differs")
+				}
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// readAll reads and closes a file, returning its content and file info.
+func readAll(file fs.File, err error) ([]byte, fs.FileInfo, error) {
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return content, info, nil
+}
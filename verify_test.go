@@ -0,0 +1,58 @@
+package githubfs
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestVerifyBackends(t *testing.T) {
+	mux, opt := setup(t)
+
+	blobs := map[string]string{
+		"a1": "hello",
+		"b2": "#!/bin/sh",
+	}
+
+	mux.HandleFunc("GET /repos/owner/repo/git/trees/{sha...}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"sha":"t1","tree":[
+			{"path":"a.txt","mode":"100644","type":"blob","sha":"a1"},
+			{"path":"link","mode":"120000","type":"blob","sha":"c3"},
+			{"path":"run.sh","mode":"100755","type":"blob","sha":"b2"}
+		]}`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/git/blobs/{sha}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(blobs[r.PathValue("sha")]))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/contents/a.txt", fileHandler("hello"))
+	mux.HandleFunc("GET /repos/owner/repo/contents/run.sh", fileHandler("#!/bin/bash"))
+
+	fsys := New(opt, WithRepository("owner", "repo"))
+
+	err := fsys.VerifyBackends(t.Context(), ".")
+	if err == nil {
+		t.Fatal("expected mismatches")
+	}
+
+	var mismatches []string
+
+	for _, err := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var mismatch *BackendMismatch
+		if !errors.As(err, &mismatch) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		mismatches = append(mismatches, mismatch.Path+" "+mismatch.Backend+" "+mismatch.Reason)
+	}
+
+	want := []string{
+		"run.sh contents content differs",
+		"run.sh contents size 11, want 9",
+		"run.sh raw content differs",
+	}
+
+	if !slices.Equal(mismatches, want) {
+		t.Errorf("unexpected mismatches:\ngot  %q\nwant %q", mismatches, want)
+	}
+}
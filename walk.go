@@ -0,0 +1,464 @@
+package githubfs
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"iter"
+	"path"
+	"sync"
+)
+
+// WalkEntry is a file or directory visited by [Walk].
+type WalkEntry struct {
+	// Path is the path of the entry (root joined with the path relative to root).
+	Path string
+
+	fs.DirEntry
+}
+
+// Walk returns an iterator over the file tree rooted at root, in lexical order (like [fs.WalkDir]).
+//
+// Errors reading a directory are yielded along with the entry of the directory and the walk continues with the next entry.
+// If root cannot be found (or prefetching its tree fails), a single error is yielded.
+// Stop iterating to end the walk early: directories are only listed as the walk reaches them.
+//
+// If fsys is an [*FS] and root is inside a repository, the entire tree is prefetched
+// using the Git Trees API (with a single request for most repositories) instead of listing every directory
+// (or using batched GraphQL queries if [WithGraphQLBackend] is enabled).
+func Walk(fsys fs.FS, root string) iter.Seq2[WalkEntry, error] {
+	ctx := context.Background()
+	if f, ok := fsys.(*FS); ok {
+		ctx = f.ctx
+	}
+
+	return walk(ctx, fsys, root)
+}
+
+// walk is like [Walk], but uses ctx for requests if fsys is an [*FS].
+func walk(ctx context.Context, fsys fs.FS, root string) iter.Seq2[WalkEntry, error] {
+	return func(yield func(WalkEntry, error) bool) {
+		if f, ok := fsys.(*FS); ok {
+			walkTree := f.walkTree
+			if f.graphql {
+				walkTree = f.walkGraphQL
+			}
+
+			entries, ok, err := walkTree(ctx, root)
+			if err != nil {
+				yield(WalkEntry{Path: root}, err)
+
+				return
+			}
+
+			if ok {
+				walkEntries(root, entries, yield)
+
+				return
+			}
+		}
+
+		info, err := statContext(ctx, fsys, root)
+		if err != nil {
+			yield(WalkEntry{Path: root}, err)
+
+			return
+		}
+
+		walkDir(ctx, fsys, root, fs.FileInfoToDirEntry(info), true, yield)
+	}
+}
+
+func walkDir(ctx context.Context, fsys fs.FS, name string, d fs.DirEntry, root bool, yield func(WalkEntry, error) bool) bool {
+	if !yield(WalkEntry{Path: name, DirEntry: d}, nil) {
+		return false
+	}
+
+	if !d.IsDir() {
+		return true
+	}
+
+	entries, err := readDirContext(ctx, fsys, name)
+	if err != nil {
+		// Inaccessible directories are skipped (see [WithSkipInaccessible])
+		if !root && errors.Is(err, fs.SkipDir) {
+			return true
+		}
+
+		return yield(WalkEntry{Path: name, DirEntry: d}, err)
+	}
+
+	for _, entry := range entries {
+		if !walkDir(ctx, fsys, path.Join(name, entry.Name()), entry, false, yield) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// statContext is like [fs.Stat], but uses ctx for requests if fsys is an [*FS].
+func statContext(ctx context.Context, fsys fs.FS, name string) (fs.FileInfo, error) {
+	if f, ok := fsys.(*FS); ok {
+		return f.StatContext(ctx, name)
+	}
+
+	return fs.Stat(fsys, name)
+}
+
+// readDirContext is like [fs.ReadDir], but uses ctx for requests if fsys is an [*FS].
+func readDirContext(ctx context.Context, fsys fs.FS, name string) ([]fs.DirEntry, error) {
+	if f, ok := fsys.(*FS); ok {
+		return f.ReadDirContext(ctx, name)
+	}
+
+	return fs.ReadDir(fsys, name)
+}
+
+// walkTree prefetches the tree at root (relative to the filesystem root).
+//
+// Returns false if root is not a directory inside a repository, so the caller can fall back to listing directories.
+// Any other error (e.g. rate limits or permission errors) is returned: listing directories would fail as well.
+func (f *FS) walkTree(ctx context.Context, root string) (treeIndex, bool, error) {
+	r, err := f.resolve("walk", root)
+	if err != nil || r.repo == "" {
+		return nil, false, nil
+	}
+
+	revision, err := f.resolveRevision(ctx, r.owner, r.repo)
+	if err != nil {
+		return nil, false, err
+	}
+
+	tree, err := f.getCompleteTree(ctx, "walk", r, revision)
+	if errors.Is(err, fs.ErrNotExist) || isNotATree(err) {
+		// root may be a file (or missing): let the caller report it.
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+
+	return f.newTreeIndex(r, revision, tree), true, nil
+}
+
+// walkEntries walks prefetched entries in lexical order.
+func walkEntries(root string, dirs treeIndex, yield func(WalkEntry, error) bool) {
+	var walk func(rel string, d fs.DirEntry) bool
+
+	walk = func(rel string, d fs.DirEntry) bool {
+		if !yield(WalkEntry{Path: path.Join(root, rel), DirEntry: d}, nil) {
+			return false
+		}
+
+		for _, entry := range dirs[rel] {
+			if !walk(path.Join(rel, entry.name), entry) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	walk(".", &dirEntry{name: path.Base(root), isDir: true})
+}
+
+// WalkTree walks the file tree rooted at root like [fs.WalkDir].
+//
+// If fsys is an [*FS] and root is inside a repository, the entire tree is fetched upfront
+// using the Git Trees API (with a single request for most repositories) instead of listing every directory.
+// Otherwise it falls back to [fs.WalkDir].
+func WalkTree(fsys fs.FS, root string, fn fs.WalkDirFunc) error {
+	fn = skipInaccessible(root, fn)
+
+	f, ok := fsys.(*FS)
+	if !ok {
+		return fs.WalkDir(fsys, root, fn)
+	}
+
+	idx, ok, err := f.walkTree(f.ctx, root)
+	if err != nil {
+		err = fn(root, nil, err)
+		if err == fs.SkipDir || err == fs.SkipAll {
+			return nil
+		}
+
+		return err
+	} else if !ok {
+		return fs.WalkDir(fsys, root, fn)
+	}
+
+	var walk func(rel string, d fs.DirEntry) error
+
+	walk = func(rel string, d fs.DirEntry) error {
+		if err := fn(path.Join(root, rel), d, nil); err != nil || !d.IsDir() {
+			if err == fs.SkipDir && d.IsDir() {
+				err = nil
+			}
+
+			return err
+		}
+
+		for _, entry := range idx[rel] {
+			if err := walk(path.Join(rel, entry.name), entry); err != nil {
+				if err == fs.SkipDir {
+					break
+				}
+
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	err = walk(".", &dirEntry{name: path.Base(root), isDir: true})
+	if err == fs.SkipDir || err == fs.SkipAll {
+		return nil
+	}
+
+	return err
+}
+
+// WalkDirConcurrent walks the file tree rooted at root like [fs.WalkDir],
+// but lists directories ahead of the walk using up to workers concurrent listings.
+//
+// fn is called from a single goroutine, in the same (lexical) order as [fs.WalkDir].
+// Listings below directories skipped by fn are dropped unless they have already started.
+func WalkDirConcurrent(fsys fs.FS, root string, workers int, fn fs.WalkDirFunc) error {
+	fn = skipInaccessible(root, fn)
+
+	l := newDirLister(fsys, max(workers, 1))
+	defer l.close()
+
+	info, err := fs.Stat(fsys, root)
+	if err != nil {
+		err = fn(root, nil, err)
+	} else {
+		d := fs.FileInfoToDirEntry(info)
+		if d.IsDir() {
+			l.list(root)
+		}
+
+		err = l.walk(root, d, fn)
+	}
+
+	if err == fs.SkipDir || err == fs.SkipAll {
+		return nil
+	}
+
+	return err
+}
+
+// skipInaccessible wraps fn so that directories below root reported as inaccessible by [FS.ReadDir]
+// (see [WithSkipInaccessible]) are skipped when fn returns the error, the way [fs.SkipDir] does.
+//
+// Errors reading root itself are returned.
+func skipInaccessible(root string, fn fs.WalkDirFunc) fs.WalkDirFunc {
+	return func(name string, d fs.DirEntry, err error) error {
+		err = fn(name, d, err)
+		if err != nil && err != fs.SkipDir && name != root && errors.Is(err, fs.SkipDir) {
+			return fs.SkipDir
+		}
+
+		return err
+	}
+}
+
+// dirLister lists directories concurrently (see [WalkDirConcurrent]).
+//
+// Listing a directory schedules listing its subdirectories.
+type dirLister struct {
+	fsys fs.FS
+	sem  chan struct{}
+	done chan struct{}
+
+	mu       sync.Mutex
+	listings map[string]*dirListing
+	skipped  map[string]bool
+
+	// skipping is closed (and replaced) whenever a directory is skipped,
+	// waking up listings waiting for a worker.
+	skipping chan struct{}
+}
+
+type dirListing struct {
+	ready   chan struct{}
+	entries []fs.DirEntry
+	err     error
+}
+
+func newDirLister(fsys fs.FS, workers int) *dirLister {
+	return &dirLister{
+		fsys:     fsys,
+		sem:      make(chan struct{}, workers),
+		done:     make(chan struct{}),
+		listings: make(map[string]*dirListing),
+		skipped:  make(map[string]bool),
+		skipping: make(chan struct{}),
+	}
+}
+
+// list schedules listing a directory.
+func (l *dirLister) list(name string) *dirListing {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if listing, ok := l.listings[name]; ok {
+		return listing
+	}
+
+	listing := &dirListing{ready: make(chan struct{})}
+	l.listings[name] = listing
+
+	if l.closed() {
+		listing.err = fs.ErrClosed
+		close(listing.ready)
+
+		return listing
+	}
+
+	if l.isSkipped(name) {
+		listing.err = fs.SkipDir
+		close(listing.ready)
+
+		return listing
+	}
+
+	go func() {
+		defer close(listing.ready)
+
+		if listing.err = l.acquire(name); listing.err != nil {
+			return
+		}
+
+		listing.entries, listing.err = fs.ReadDir(l.fsys, name)
+		<-l.sem
+
+		for _, entry := range listing.entries {
+			if entry.IsDir() {
+				l.list(path.Join(name, entry.Name()))
+			}
+		}
+	}()
+
+	return listing
+}
+
+// acquire waits for a free worker to list a directory.
+//
+// It gives up when the lister is closed or the directory is skipped while waiting.
+func (l *dirLister) acquire(name string) error {
+	for {
+		l.mu.Lock()
+		skipped, skipping := l.isSkipped(name), l.skipping
+		l.mu.Unlock()
+
+		if skipped {
+			return fs.SkipDir
+		}
+
+		// Both cases may be ready at once: a closed lister must not start new listings.
+		if l.closed() {
+			return fs.ErrClosed
+		}
+
+		select {
+		case l.sem <- struct{}{}:
+			if l.closed() {
+				<-l.sem
+
+				return fs.ErrClosed
+			}
+
+			return nil
+		case <-l.done:
+			return fs.ErrClosed
+		case <-skipping:
+		}
+	}
+}
+
+// skip drops scheduled listings of a directory and everything below it.
+func (l *dirLister) skip(name string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.skipped[name] = true
+
+	close(l.skipping)
+	l.skipping = make(chan struct{})
+}
+
+// isSkipped reports whether a directory or one of its parents has been skipped.
+//
+// The caller must hold l.mu.
+func (l *dirLister) isSkipped(name string) bool {
+	for {
+		if l.skipped[name] {
+			return true
+		}
+
+		parent := path.Dir(name)
+		if parent == name {
+			return false
+		}
+
+		name = parent
+	}
+}
+
+// closed reports whether the lister has been closed.
+func (l *dirLister) closed() bool {
+	select {
+	case <-l.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// close stops scheduled listings that have not started yet.
+func (l *dirLister) close() {
+	close(l.done)
+}
+
+// walk mirrors the traversal of [fs.WalkDir], waiting for listings as it reaches directories.
+func (l *dirLister) walk(name string, d fs.DirEntry, fn fs.WalkDirFunc) error {
+	if err := fn(name, d, nil); err != nil || !d.IsDir() {
+		if err == fs.SkipDir && d.IsDir() {
+			l.skip(name)
+
+			err = nil
+		}
+
+		return err
+	}
+
+	listing := l.list(name)
+	<-listing.ready
+
+	if listing.err != nil {
+		if err := fn(name, d, listing.err); err != nil {
+			if err == fs.SkipDir && d.IsDir() {
+				err = nil
+			}
+
+			return err
+		}
+	}
+
+	for _, entry := range listing.entries {
+		if err := l.walk(path.Join(name, entry.Name()), entry, fn); err != nil {
+			if err == fs.SkipDir {
+				// The remaining entries are skipped along with everything below them.
+				l.skip(name)
+
+				break
+			}
+
+			return err
+		}
+	}
+
+	return nil
+}
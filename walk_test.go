@@ -0,0 +1,372 @@
+package githubfs
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestWalk(t *testing.T) {
+	mux, opt := setup(t)
+
+	var trees int
+
+	mux.HandleFunc("GET /repos/owner/repo/git/trees/{sha...}", func(w http.ResponseWriter, r *http.Request) {
+		trees++
+
+		w.Write([]byte(`{"tree":[
+			{"path":"a","type":"tree","mode":"040000"},
+			{"path":"a-b.txt","type":"blob","mode":"100644","size":1},
+			{"path":"a/run.sh","type":"blob","mode":"100755","size":2},
+			{"path":"lib","type":"commit","mode":"160000"}
+		]}`))
+	})
+
+	var got []string
+
+	for entry, err := range Walk(New(opt, WithRepository("owner", "repo")), ".") {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got = append(got, entry.Path)
+
+		if entry.Path == "a/run.sh" {
+			info, err := entry.Info()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if info.Mode() != 0o755 || info.Size() != 2 {
+				t.Errorf("unexpected file info: %v %d", info.Mode(), info.Size())
+			}
+		}
+	}
+
+	// Same order as fs.WalkDir
+	var want []string
+
+	fs.WalkDir(fstest.MapFS{"a/run.sh": {}, "a-b.txt": {}}, ".", func(path string, d fs.DirEntry, err error) error {
+		want = append(want, path)
+
+		return err
+	})
+
+	if !slices.Equal(got, want) {
+		t.Errorf("unexpected walk order: got %v, want %v", got, want)
+	}
+
+	if trees != 1 {
+		t.Errorf("expected the tree to be prefetched with a single request, got %d", trees)
+	}
+}
+
+func TestWalkLazy(t *testing.T) {
+	mux, opt := setup(t)
+
+	var listings []string
+
+	mux.HandleFunc("GET /users/owner", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"login":"owner"}`))
+	})
+	mux.HandleFunc("GET /users/owner/repos", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"name":"a"},{"name":"b"}]`))
+	})
+	mux.HandleFunc("GET /repos/owner/{repo}/contents/", func(w http.ResponseWriter, r *http.Request) {
+		listings = append(listings, r.PathValue("repo"))
+
+		if r.PathValue("repo") == "a" {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		w.Write([]byte(`[{"type":"file","name":"README.md"}]`))
+	})
+
+	var got []string
+
+	for entry, err := range Walk(New(opt, WithOwner("owner")), ".") {
+		if err != nil {
+			got = append(got, "error: "+entry.Path)
+
+			continue
+		}
+
+		got = append(got, entry.Path)
+
+		if entry.Path == "b" {
+			break
+		}
+	}
+
+	if want := []string{".", "a", "error: a", "b"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected walk: got %v, want %v", got, want)
+	}
+
+	if want := []string{"a"}; !slices.Equal(listings, want) {
+		t.Errorf("expected directories to be listed as the walk reaches them, got %v", listings)
+	}
+}
+
+func TestWalkTree(t *testing.T) {
+	mux, opt := setup(t)
+
+	var trees int
+
+	mux.HandleFunc("GET /repos/owner/repo/git/trees/{sha...}", func(w http.ResponseWriter, r *http.Request) {
+		trees++
+
+		if got, want := r.PathValue("sha"), "HEAD:src"; got != want {
+			t.Errorf("unexpected tree-ish: got %q, want %q", got, want)
+		}
+
+		w.Write([]byte(`{"tree":[
+			{"path":"a","type":"tree","mode":"040000"},
+			{"path":"a/skipped.txt","type":"blob","mode":"100644","size":1},
+			{"path":"b","type":"tree","mode":"040000"},
+			{"path":"b/main.go","type":"blob","mode":"100644","size":2},
+			{"path":"c.txt","type":"blob","mode":"100644","size":3}
+		]}`))
+	})
+
+	var got []string
+
+	err := WalkTree(New(opt, WithRepository("owner", "repo")), "src", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		got = append(got, path)
+
+		switch path {
+		case "src/a":
+			return fs.SkipDir
+		case "src/b/main.go":
+			return fs.SkipAll
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"src", "src/a", "src/b", "src/b/main.go"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected walk: got %v, want %v", got, want)
+	}
+
+	if trees != 1 {
+		t.Errorf("expected a single tree request, got %d", trees)
+	}
+}
+
+func TestWalkTreeError(t *testing.T) {
+	mux, opt := setup(t)
+
+	mux.HandleFunc("GET /repos/owner/repo/git/trees/{sha...}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"message":"Resource not accessible by integration"}`))
+	})
+	mux.HandleFunc("GET /repos/owner/repo/contents/", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("expected the walk not to fall back to listing directories")
+	})
+
+	var errs []error
+
+	err := WalkTree(New(opt, WithRepository("owner", "repo")), ".", func(path string, d fs.DirEntry, err error) error {
+		errs = append(errs, err)
+
+		return err
+	})
+
+	var permErr *PermissionError
+
+	if !errors.As(err, &permErr) {
+		t.Errorf("expected a permission error, got %v", err)
+	}
+
+	if len(errs) != 1 || !errors.As(errs[0], &permErr) {
+		t.Errorf("expected the error to be passed to fn, got %v", errs)
+	}
+
+	errs = nil
+
+	for _, err := range Walk(New(opt, WithRepository("owner", "repo")), ".") {
+		errs = append(errs, err)
+	}
+
+	if len(errs) != 1 || !errors.As(errs[0], &permErr) {
+		t.Errorf("expected the error to be yielded, got %v", errs)
+	}
+}
+
+func TestWalkTreeEscape(t *testing.T) {
+	mux, opt := setup(t)
+
+	mux.HandleFunc("GET /repos/owner/repo/git/trees/{sha...}", func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.PathValue("sha"), "HEAD:c#/50%?"; got != want {
+			t.Errorf("unexpected tree-ish: got %q, want %q", got, want)
+		}
+
+		w.Write([]byte(`{"tree":[{"path":"main.c","type":"blob","mode":"100644","size":1}]}`))
+	})
+
+	var got []string
+
+	err := WalkTree(New(opt, WithRepository("owner", "repo")), "c#/50%?", func(path string, d fs.DirEntry, err error) error {
+		got = append(got, path)
+
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"c#/50%?", "c#/50%?/main.c"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected walk: got %v, want %v", got, want)
+	}
+}
+
+func TestWalkDirConcurrentClose(t *testing.T) {
+	mapFS := fstest.MapFS{}
+	for i := range 20 {
+		for j := range 5 {
+			mapFS[fmt.Sprintf("%02d/%d/file.txt", i, j)] = &fstest.MapFile{}
+		}
+	}
+
+	fsys := &slowFS{FS: mapFS}
+
+	err := WalkDirConcurrent(fsys, ".", 4, func(path string, d fs.DirEntry, err error) error {
+		if path == "00" {
+			return fs.SkipAll
+		}
+
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fsys.mu.Lock()
+	listed := len(fsys.listed)
+	fsys.mu.Unlock()
+
+	// Let listings in flight finish.
+	time.Sleep(50 * time.Millisecond)
+
+	fsys.mu.Lock()
+	defer fsys.mu.Unlock()
+
+	if len(fsys.listed) != listed {
+		t.Errorf("expected no listings to start after the walk returned, got %d more", len(fsys.listed)-listed)
+	}
+}
+
+// slowFS delays listings and records the maximum number of concurrent listings.
+type slowFS struct {
+	fs.FS
+
+	mu       sync.Mutex
+	inFlight int
+	peak     int
+	listed   []string
+}
+
+func (s *slowFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	s.mu.Lock()
+	s.inFlight++
+	s.peak = max(s.peak, s.inFlight)
+	s.listed = append(s.listed, name)
+	s.mu.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+
+	s.mu.Lock()
+	s.inFlight--
+	s.mu.Unlock()
+
+	return fs.ReadDir(s.FS, name)
+}
+
+func TestWalkDirConcurrent(t *testing.T) {
+	mapFS := fstest.MapFS{}
+	for _, dir := range []string{"a", "b", "c", "d"} {
+		for _, sub := range []string{"x", "y"} {
+			mapFS[dir+"/"+sub+"/file.txt"] = &fstest.MapFile{}
+		}
+	}
+
+	walk := func(walkDir func(fn fs.WalkDirFunc) error) []string {
+		var paths []string
+
+		err := walkDir(func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			paths = append(paths, path)
+
+			if path == "b/x" {
+				return fs.SkipDir
+			}
+
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return paths
+	}
+
+	fsys := &slowFS{FS: mapFS}
+
+	got := walk(func(fn fs.WalkDirFunc) error { return WalkDirConcurrent(fsys, ".", 4, fn) })
+	want := walk(func(fn fs.WalkDirFunc) error { return fs.WalkDir(mapFS, ".", fn) })
+
+	if !slices.Equal(got, want) {
+		t.Errorf("unexpected walk order:\ngot  %v\nwant %v", got, want)
+	}
+
+	if fsys.peak < 2 || fsys.peak > 4 {
+		t.Errorf("expected up to 4 concurrent listings, got %d", fsys.peak)
+	}
+}
+
+func TestWalkDirConcurrentSkip(t *testing.T) {
+	mapFS := fstest.MapFS{}
+	for i := range 10 {
+		mapFS[fmt.Sprintf("a/%d/x/file.txt", i)] = &fstest.MapFile{}
+		mapFS[fmt.Sprintf("b/%d/file.txt", i)] = &fstest.MapFile{}
+	}
+
+	fsys := &slowFS{FS: mapFS}
+
+	err := WalkDirConcurrent(fsys, ".", 1, func(path string, d fs.DirEntry, err error) error {
+		if path == "a" {
+			return fs.SkipDir
+		}
+
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range fsys.listed {
+		if strings.HasPrefix(name, "a/") {
+			t.Errorf("expected no listings below a skipped directory, got %v", fsys.listed)
+
+			break
+		}
+	}
+}
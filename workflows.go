@@ -0,0 +1,316 @@
+package githubfs
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"maps"
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// WorkflowFS is a filesystem of GitHub Actions workflows across the repositories of an owner.
+//
+// Workflows are mounted at "<owner>/<repo>/<file>" (read from the default branch of each repository).
+// The owner's ".github" repository is mounted as a whole.
+//
+// Reusable workflows referenced by "uses:" keys are followed (recursively)
+// and mounted at "<owner>/<repo>@<ref>/<file>", so the reference graph can be traversed through the filesystem.
+// Refs are path escaped, so a workflow referenced at "feature/x" is mounted at "<owner>/<repo>@feature%2Fx".
+type WorkflowFS struct {
+	mounts map[string]*FS
+	uses   map[string][]string
+	errs   []error
+
+	// workflowDirs holds the workflow directory relative to the mount root (for mounts of entire repositories).
+	workflowDirs map[string]string
+}
+
+// NewWorkflowFS discovers the workflows of an owner and the reusable workflows they reference.
+//
+// Only listing the repositories of the owner (or canceling ctx) fails discovery.
+// Repositories and workflows that cannot be read are skipped, and their errors are reported by [WorkflowFS.Errors].
+func NewWorkflowFS(ctx context.Context, fsys *FS, owner string) (*WorkflowFS, error) {
+	root := fsys.clone(ref{})
+
+	w := &WorkflowFS{
+		mounts:       make(map[string]*FS),
+		uses:         make(map[string][]string),
+		workflowDirs: make(map[string]string),
+	}
+
+	repos, err := root.readDir(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	// skip records an error of a single repository or workflow, so discovery can continue with the rest.
+	// Canceling ctx still aborts discovery.
+	skip := func(err error) error {
+		if ctx.Err() != nil {
+			return err
+		}
+
+		w.errs = append(w.errs, err)
+
+		return nil
+	}
+
+	var queue []string
+
+	for _, repo := range repos {
+		mount := path.Join(owner, repo.Name())
+
+		workflows, err := root.SubWithOptions(path.Join(mount, ".github/workflows"))
+		if err != nil {
+			if err := skip(err); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		files, err := workflowFiles(ctx, workflows)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			if err := skip(err); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		if repo.Name() == ".github" {
+			// Mount the entire owner-level .github repository (workflow templates, shared configuration, etc.).
+			w.mounts[mount] = root.clone(root.ref.join(mount))
+			w.workflowDirs[mount] = ".github/workflows"
+		} else if len(files) > 0 {
+			w.mounts[mount] = workflows
+		}
+
+		for _, file := range files {
+			queue = append(queue, path.Join(mount, w.workflowDirs[mount], file))
+		}
+	}
+
+	seen := make(map[string]bool)
+
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		content, err := w.readFile(ctx, name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			if err := skip(err); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		for _, uses := range parseWorkflowUses(content) {
+			target, ok := w.resolveUses(name, uses)
+			if !ok {
+				continue
+			}
+
+			mount := mountOf(target)
+
+			if _, ok := w.mounts[mount]; !ok {
+				repo, revision, _ := strings.Cut(path.Base(mount), "@")
+
+				revision, err := url.PathUnescape(revision)
+				if err != nil {
+					if err := skip(err); err != nil {
+						return nil, err
+					}
+
+					continue
+				}
+
+				sub, err := root.SubWithOptions(path.Join(path.Dir(mount), repo, ".github/workflows"), WithRef(revision), WithRefAtTime(time.Time{}))
+				if err != nil {
+					if err := skip(err); err != nil {
+						return nil, err
+					}
+
+					continue
+				}
+
+				w.mounts[mount] = sub
+			}
+
+			w.uses[name] = append(w.uses[name], target)
+			queue = append(queue, target)
+		}
+	}
+
+	return w, nil
+}
+
+// Errors returns the errors of the repositories and workflows skipped during discovery
+// (e.g. repositories whose workflows cannot be listed due to fine-grained token restrictions).
+func (w *WorkflowFS) Errors() []error {
+	return slices.Clone(w.errs)
+}
+
+// Uses returns the paths of the reusable workflows referenced by a workflow.
+func (w *WorkflowFS) Uses(name string) []string {
+	return slices.Clone(w.uses[name])
+}
+
+// Open implements the [fs.FS] interface.
+func (w *WorkflowFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	if name == "." || !strings.Contains(name, "/") {
+		return w.openVirtualDir(name)
+	}
+
+	fsys, rest, ok := w.mount(name)
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	return fsys.Open(rest)
+}
+
+// readFile reads a workflow file using ctx.
+func (w *WorkflowFS) readFile(ctx context.Context, name string) ([]byte, error) {
+	fsys, rest, ok := w.mount(name)
+	if !ok {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+	}
+
+	return fsys.ReadFileContext(ctx, rest)
+}
+
+// mount returns the mount a path belongs to and the path relative to it.
+func (w *WorkflowFS) mount(name string) (*FS, string, bool) {
+	segments := strings.SplitN(name, "/", 3)
+	if len(segments) < 2 {
+		return nil, "", false
+	}
+
+	fsys, ok := w.mounts[path.Join(segments[0], segments[1])]
+	if !ok {
+		return nil, "", false
+	}
+
+	rest := "."
+	if len(segments) == 3 {
+		rest = segments[2]
+	}
+
+	return fsys, rest, true
+}
+
+// openVirtualDir lists owners (at the root) or mounts of an owner.
+func (w *WorkflowFS) openVirtualDir(name string) (fs.File, error) {
+	names := make(map[string]bool)
+
+	for mount := range w.mounts {
+		owner, repo, _ := strings.Cut(mount, "/")
+
+		switch name {
+		case ".":
+			names[owner] = true
+		case owner:
+			names[repo] = true
+		}
+	}
+
+	if len(names) == 0 && name != "." {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	entries := make([]*dirEntry, 0, len(names))
+	for _, name := range slices.Sorted(maps.Keys(names)) {
+		entries = append(entries, &dirEntry{name: name, isDir: true})
+	}
+
+	return &dir{name: path.Base(name), entries: entries}, nil
+}
+
+// resolveUses resolves a "uses:" value found in a workflow to a path in the filesystem.
+func (w *WorkflowFS) resolveUses(workflow string, uses string) (string, bool) {
+	// Local reusable workflows are referenced relative to the repository root.
+	if local, ok := strings.CutPrefix(uses, "./.github/workflows/"); ok {
+		mount := mountOf(workflow)
+
+		return path.Join(mount, w.workflowDirs[mount], local), true
+	}
+
+	// The ref is split off before any path handling, since it may contain slashes.
+	target, revision, ok := strings.Cut(uses, "@")
+	if !ok {
+		return "", false
+	}
+
+	segments := strings.SplitN(target, "/", 3)
+	if len(segments) != 3 {
+		// Actions (owner/repo@ref) are not workflows
+		return "", false
+	}
+
+	file, ok := strings.CutPrefix(segments[2], ".github/workflows/")
+	if !ok || strings.Contains(file, "/") {
+		return "", false
+	}
+
+	return path.Join(segments[0], segments[1]+"@"+url.PathEscape(revision), file), true
+}
+
+var workflowUsesRegexp = regexp.MustCompile(`(?m)^\s*(?:-\s*)?uses:\s*["']?([^"'\s#]+)`)
+
+// parseWorkflowUses returns the values of "uses:" keys in a workflow file.
+func parseWorkflowUses(content []byte) []string {
+	var uses []string
+
+	for _, match := range workflowUsesRegexp.FindAllSubmatch(content, -1) {
+		uses = append(uses, string(match[1]))
+	}
+
+	return uses
+}
+
+// workflowFiles lists workflow files in a directory.
+func workflowFiles(ctx context.Context, fsys *FS) ([]string, error) {
+	entries, err := fsys.readDir(ctx, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		if ext := path.Ext(entry.Name()); ext == ".yml" || ext == ".yaml" {
+			files = append(files, entry.Name())
+		}
+	}
+
+	return files, nil
+}
+
+// mountOf returns the mount (<owner>/<repo>[@<ref>]) a path belongs to.
+func mountOf(name string) string {
+	segments := strings.SplitN(name, "/", 3)
+
+	return path.Join(segments[0], segments[1])
+}
@@ -0,0 +1,137 @@
+package githubfs
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestWorkflowFS(t *testing.T) {
+	mux, opt := setup(t)
+
+	mux.HandleFunc("GET /users/owner/repos", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"name":".github"},{"name":"app"},{"name":"lib"}]`))
+	})
+	mux.HandleFunc("GET /repos/owner/app/contents/.github/workflows", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"type":"file","name":"ci.yaml"},{"type":"file","name":"local.yaml"},{"type":"file","name":"README.md"}]`))
+	})
+	mux.HandleFunc("GET /repos/owner/app/contents/.github/workflows/ci.yaml", fileHandler(`
+jobs:
+  build:
+    uses: owner/lib/.github/workflows/build.yaml@v1
+  release:
+    uses: owner/lib/.github/workflows/release.yaml@feature/x
+  local:
+    uses: "./.github/workflows/local.yaml"
+  test:
+    steps:
+      - uses: actions/checkout@v4
+`))
+	mux.HandleFunc("GET /repos/owner/app/contents/.github/workflows/local.yaml", fileHandler("jobs: {}"))
+	mux.HandleFunc("GET /repos/owner/lib/contents/.github/workflows/build.yaml", func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.URL.Query().Get("ref"), "v1"; got != want {
+			t.Errorf("unexpected ref: got %q, want %q", got, want)
+		}
+
+		fileHandler("jobs: {}")(w, r)
+	})
+	mux.HandleFunc("GET /repos/owner/lib/contents/.github/workflows/release.yaml", func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.URL.Query().Get("ref"), "feature/x"; got != want {
+			t.Errorf("unexpected ref: got %q, want %q", got, want)
+		}
+
+		fileHandler("jobs: {}")(w, r)
+	})
+	mux.HandleFunc("GET /repos/owner/.github/contents/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"type":"dir","name":"workflow-templates"}]`))
+	})
+
+	fsys, err := NewWorkflowFS(t.Context(), New(opt), "owner")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := fs.ReadDir(fsys, "owner")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+
+	if want := []string{".github", "app", "lib@feature%2Fx", "lib@v1"}; !slices.Equal(names, want) {
+		t.Errorf("unexpected mounts: got %v, want %v", names, want)
+	}
+
+	uses := fsys.Uses("owner/app/ci.yaml")
+	if want := []string{"owner/lib@v1/build.yaml", "owner/lib@feature%2Fx/release.yaml", "owner/app/local.yaml"}; !slices.Equal(uses, want) {
+		t.Errorf("unexpected references: got %v, want %v", uses, want)
+	}
+
+	if _, err := fs.ReadFile(fsys, "owner/lib@v1/build.yaml"); err != nil {
+		t.Errorf("failed to read referenced workflow: %v", err)
+	}
+
+	if _, err := fs.ReadFile(fsys, "owner/lib@feature%2Fx/release.yaml"); err != nil {
+		t.Errorf("failed to read workflow referenced at a ref containing slashes: %v", err)
+	}
+
+	entries, err = fs.ReadDir(fsys, "owner/.github")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 1 || entries[0].Name() != "workflow-templates" {
+		t.Errorf("expected the entire .github repository to be mounted, got %v", entries)
+	}
+
+	if _, err := fs.ReadDir(fsys, "other"); err == nil {
+		t.Error("expected unknown owner to fail")
+	}
+}
+
+func TestWorkflowFSSkipsInaccessibleRepositories(t *testing.T) {
+	mux, opt := setup(t)
+
+	mux.HandleFunc("GET /users/owner/repos", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"name":"app"},{"name":"private"}]`))
+	})
+	mux.HandleFunc("GET /repos/owner/app/contents/.github/workflows", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"type":"file","name":"ci.yaml"}]`))
+	})
+	mux.HandleFunc("GET /repos/owner/app/contents/.github/workflows/ci.yaml", fileHandler(`
+jobs:
+  build:
+    uses: owner/lib/.github/workflows/build.yaml@v1
+`))
+	mux.HandleFunc("GET /repos/owner/lib/contents/.github/workflows/build.yaml", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"Resource not accessible by personal access token"}`, http.StatusForbidden)
+	})
+	mux.HandleFunc("GET /repos/owner/private/contents/.github/workflows", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"Resource not accessible by personal access token"}`, http.StatusForbidden)
+	})
+
+	fsys, err := NewWorkflowFS(t.Context(), New(opt), "owner")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := fs.ReadFile(fsys, "owner/app/ci.yaml"); err != nil {
+		t.Errorf("failed to read workflow of accessible repository: %v", err)
+	}
+
+	errs := fsys.Errors()
+	if len(errs) != 2 {
+		t.Fatalf("expected errors of the inaccessible repository and workflow, got %v", errs)
+	}
+
+	for _, err := range errs {
+		if !errors.Is(err, fs.ErrPermission) {
+			t.Errorf("expected permission error, got %v", err)
+		}
+	}
+}